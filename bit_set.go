@@ -0,0 +1,260 @@
+package wtype
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// MaxBitSetValue is the largest element a BitSet can hold; a set holding it uses 512 MiB.
+const MaxBitSetValue uint = 1<<32 - 1
+
+// ErrBitSetValue is returned by TryAdd and UnmarshalJSON for values above MaxBitSetValue.
+var ErrBitSetValue = errors.New("wtype: BitSet value is above MaxBitSetValue")
+
+// BitSet is a compact, non-thread-safe set of small non-negative integers.
+//
+//	Element i is bit i%64 of word i/64, so memory is proportional to the largest element,
+//	not to the number of elements. Prefer Set for sparse or very large values.
+//	Values above MaxBitSetValue cannot be represented: Add panics on them and TryAdd returns an error.
+type BitSet struct {
+	words []uint64
+}
+
+// MarshalJSON implementation json.Marshal
+//
+//	The elements are written in ascending order.
+func (s BitSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Get())
+}
+
+// UnmarshalJSON implementation json.Unmarshal
+func (s *BitSet) UnmarshalJSON(bytes []byte) error {
+	var data []uint
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return err
+	}
+	for _, v := range data {
+		if v > MaxBitSetValue {
+			return fmt.Errorf("%w: %d", ErrBitSetValue, v)
+		}
+	}
+	s.Clear()
+	for _, v := range data {
+		s.Add(v)
+	}
+	return nil
+}
+
+// MarshalBinary implementation encoding.BinaryMarshaler
+//
+//	The words are written in little-endian order, without trailing empty words.
+func (s *BitSet) MarshalBinary() ([]byte, error) {
+	words := s.words
+	for len(words) > 0 && words[len(words)-1] == 0 {
+		words = words[:len(words)-1]
+	}
+	b := make([]byte, 0, len(words)*8)
+	for _, w := range words {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implementation encoding.BinaryUnmarshaler
+func (s *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data)%8 != 0 {
+		return errors.New("wtype: BitSet binary data length is not a multiple of 8")
+	}
+	s.words = make([]uint64, len(data)/8)
+	for i := range s.words {
+		s.words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return nil
+}
+
+// Values is an alias for Get.
+func (s *BitSet) Values() []uint {
+	return s.Get()
+}
+
+// Add adds an element to the set.
+//
+//	The set grows to data/8 bytes, which is 512 MiB for MaxBitSetValue.
+//	It panics if data is above MaxBitSetValue; use TryAdd for values that are not known to fit.
+func (s *BitSet) Add(data uint) {
+	if err := s.TryAdd(data); err != nil {
+		panic(err)
+	}
+}
+
+// TryAdd adds an element to the set, or returns ErrBitSetValue if it is above MaxBitSetValue.
+func (s *BitSet) TryAdd(data uint) error {
+	if data > MaxBitSetValue {
+		return fmt.Errorf("%w: %d", ErrBitSetValue, data)
+	}
+	i := int(data / 64)
+	if i >= len(s.words) {
+		s.words = append(s.words, make([]uint64, i+1-len(s.words))...)
+	}
+	s.words[i] |= 1 << (data % 64)
+	return nil
+}
+
+// Get returns all elements in the set as a slice, in ascending order.
+func (s *BitSet) Get() []uint {
+	ret := make([]uint, 0, s.PopCount())
+	s.Range(func(v uint) bool {
+		ret = append(ret, v)
+		return true
+	})
+	return ret
+}
+
+// Len returns the number of elements in the set.
+func (s *BitSet) Len() int {
+	return s.PopCount()
+}
+
+// PopCount returns the number of set bits, which is the number of elements.
+func (s *BitSet) PopCount() int {
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Remove removes an element from the set.
+func (s *BitSet) Remove(data uint) {
+	if i := data / 64; i < uint(len(s.words)) {
+		s.words[i] &^= 1 << (data % 64)
+	}
+}
+
+// Contains checks if an element exists in the set.
+func (s *BitSet) Contains(data uint) bool {
+	i := data / 64
+	return i < uint(len(s.words)) && s.words[i]&(1<<(data%64)) != 0
+}
+
+// Clear removes all elements from the set.
+func (s *BitSet) Clear() {
+	s.words = nil
+}
+
+// NextSet returns the smallest element greater than or equal to i.
+//
+//	It returns false if there is no such element. Use it to iterate without a callback:
+//
+//	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {}
+func (s *BitSet) NextSet(i uint) (uint, bool) {
+	w := i / 64
+	if w >= uint(len(s.words)) {
+		return 0, false
+	}
+	word := s.words[w] >> (i % 64)
+	if word != 0 {
+		return i + uint(bits.TrailingZeros64(word)), true
+	}
+	for w++; w < uint(len(s.words)); w++ {
+		if s.words[w] != 0 {
+			return w*64 + uint(bits.TrailingZeros64(s.words[w])), true
+		}
+	}
+	return 0, false
+}
+
+// Range iterates over the set in ascending order and calls f for each element.
+//
+//	If f returns false, the iteration stops.
+func (s *BitSet) Range(f func(uint) bool) {
+	for i, w := range s.words {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			if !f(uint(i*64 + b)) {
+				return
+			}
+			w &= w - 1
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set in ascending order.
+func (s *BitSet) All() iter.Seq[uint] {
+	return s.Range
+}
+
+// Union returns a new set with the elements that are in s or other.
+func (s *BitSet) Union(other *BitSet) *BitSet {
+	long, short := s.words, other.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	words := make([]uint64, len(long))
+	copy(words, long)
+	for i, w := range short {
+		words[i] |= w
+	}
+	return &BitSet{words: words}
+}
+
+// Intersection returns a new set with the elements that are in both s and other.
+func (s *BitSet) Intersection(other *BitSet) *BitSet {
+	words := make([]uint64, min(len(s.words), len(other.words)))
+	for i := range words {
+		words[i] = s.words[i] & other.words[i]
+	}
+	return &BitSet{words: words}
+}
+
+// Difference returns a new set with the elements of s that are not in other.
+func (s *BitSet) Difference(other *BitSet) *BitSet {
+	words := make([]uint64, len(s.words))
+	copy(words, s.words)
+	for i := range min(len(words), len(other.words)) {
+		words[i] &^= other.words[i]
+	}
+	return &BitSet{words: words}
+}
+
+// SymmetricDifference returns a new set with the elements that are in exactly one of s and other.
+func (s *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	ret := s.Union(other)
+	for i := range min(len(s.words), len(other.words)) {
+		ret.words[i] = s.words[i] ^ other.words[i]
+	}
+	return ret
+}
+
+// Equal reports whether s and other have the same elements.
+func (s *BitSet) Equal(other *BitSet) bool {
+	long, short := s.words, other.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	for i, w := range long {
+		if i < len(short) {
+			if w != short[i] {
+				return false
+			}
+		} else if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// NewBitSet creates a new BitSet with val.
+//
+//	It panics if a value is above MaxBitSetValue.
+func NewBitSet(val ...uint) *BitSet {
+	s := &BitSet{}
+	for _, v := range val {
+		s.Add(v)
+	}
+	return s
+}
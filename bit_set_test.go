@@ -0,0 +1,147 @@
+package wtype_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"slices"
+	"testing"
+
+	"github.com/wuchieh/wtype"
+)
+
+var _ wtype.ISet[uint] = (*wtype.BitSet)(nil)
+
+func TestBitSet(t *testing.T) {
+	s := wtype.NewBitSet(3, 64, 0, 200, 3)
+	if got := s.Values(); !slices.Equal(got, []uint{0, 3, 64, 200}) {
+		t.Errorf("expected [0 3 64 200], got %v", got)
+	}
+	if s.Len() != 4 || s.PopCount() != 4 {
+		t.Errorf("expected 4 elements, got %d", s.Len())
+	}
+	if !s.Contains(64) || s.Contains(65) || s.Contains(10000) {
+		t.Error("unexpected Contains result")
+	}
+
+	var got []uint
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		got = append(got, i)
+	}
+	if !slices.Equal(got, []uint{0, 3, 64, 200}) {
+		t.Errorf("expected NextSet to visit [0 3 64 200], got %v", got)
+	}
+	if v, ok := s.NextSet(65); !ok || v != 200 {
+		t.Errorf("expected NextSet(65) 200, got (%d, %v)", v, ok)
+	}
+	if _, ok := s.NextSet(201); ok {
+		t.Error("expected no element after 200")
+	}
+
+	s.Remove(64)
+	s.Remove(5000)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []uint{0, 3, 200}) {
+		t.Errorf("expected [0 3 200], got %v", got)
+	}
+
+	var zero wtype.BitSet
+	zero.Add(1)
+	if !zero.Contains(1) {
+		t.Error("expected the zero BitSet to be usable")
+	}
+	zero.Clear()
+	if zero.Len() != 0 {
+		t.Error("expected an empty set")
+	}
+}
+
+func TestBitSet_Algebra(t *testing.T) {
+	a := wtype.NewBitSet(1, 2, 3, 100)
+	b := wtype.NewBitSet(3, 4, 300)
+
+	tests := []struct {
+		name string
+		got  *wtype.BitSet
+		want []uint
+	}{
+		{"Union", a.Union(b), []uint{1, 2, 3, 4, 100, 300}},
+		{"Intersection", a.Intersection(b), []uint{3}},
+		{"Difference", a.Difference(b), []uint{1, 2, 100}},
+		{"Difference reversed", b.Difference(a), []uint{4, 300}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []uint{1, 2, 4, 100, 300}},
+	}
+	for _, tt := range tests {
+		if got := tt.got.Values(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	c := wtype.NewBitSet(1, 500)
+	c.Remove(500)
+	if !c.Equal(wtype.NewBitSet(1)) || c.Equal(a) {
+		t.Error("unexpected Equal result")
+	}
+}
+
+func TestBitSet_Marshal(t *testing.T) {
+	s := wtype.NewBitSet(5, 1, 130)
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[1,5,130]" {
+		t.Errorf("expected [1,5,130], got %s", b)
+	}
+	var fromJSON wtype.BitSet
+	if err := json.Unmarshal(b, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !fromJSON.Equal(s) {
+		t.Errorf("expected %v, got %v", s.Values(), fromJSON.Values())
+	}
+
+	bin, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bin) != 24 {
+		t.Errorf("expected 3 words, got %d bytes", len(bin))
+	}
+	var fromBinary wtype.BitSet
+	if err := fromBinary.UnmarshalBinary(bin); err != nil {
+		t.Fatal(err)
+	}
+	if !fromBinary.Equal(s) {
+		t.Errorf("expected %v, got %v", s.Values(), fromBinary.Values())
+	}
+	if err := fromBinary.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Error("expected an error for a truncated word")
+	}
+}
+
+func TestBitSet_Limit(t *testing.T) {
+	if bits.UintSize == 32 {
+		t.Skip("every uint is representable on 32-bit platforms")
+	}
+	s := wtype.NewBitSet(1)
+	if err := s.TryAdd(^uint(0)); !errors.Is(err, wtype.ErrBitSetValue) {
+		t.Errorf("expected ErrBitSetValue, got %v", err)
+	}
+	if s.Len() != 1 || s.Contains(^uint(0)) {
+		t.Errorf("expected the set to be unchanged, got %d elements", s.Len())
+	}
+	s.Remove(^uint(0))
+
+	var fromJSON wtype.BitSet
+	if err := json.Unmarshal([]byte("[18446744073709551615]"), &fromJSON); !errors.Is(err, wtype.ErrBitSetValue) {
+		t.Errorf("expected ErrBitSetValue, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Add to panic for a value above MaxBitSetValue")
+		}
+	}()
+	s.Add(^uint(0))
+}
@@ -2,69 +2,189 @@ package wtype
 
 import "time"
 
+// Cache holds a single value that is reset to its zero value once its duration has elapsed.
+//
+//	Expiry is deadline based: no timer or goroutine is used, and expired data is reset when it is next
+//	accessed or when DeleteExpired is called, for example by a Janitor.
 type Cache[T any] struct {
-	data T
-	d    time.Duration
-	t    *time.Timer
+	data     T
+	d        time.Duration
+	expireAt time.Time // zero while the data never expires
+	ok       bool      // whether data has been set and not reset since
+	created  time.Time // when the data was last set
+	sliding  bool
+	maxLife  time.Duration
+	jitter   jitter
+	clock    Clock  // nil means RealClock
+	version  uint64 // incremented whenever the data is set or reset
+	events   cacheEvents[T]
+	stats    cacheStats
+}
+
+// now returns the current time of the cache clock.
+func (c *Cache[T]) now() time.Time {
+	return clockOrReal(c.clock).Now()
 }
 
-// setTimer sets the timer of the cache.
+// setTimer sets the expiry of the data from the duration of the cache.
 func (c *Cache[T]) setTimer() {
-	if c.t != nil {
-		c.t.Stop()
-	}
+	c.setTimerFor(c.d)
+}
 
-	d := c.d
-	if d == 0 {
-		return
+// setTimerFor sets the data to expire after d instead of the duration of the cache.
+func (c *Cache[T]) setTimerFor(d time.Duration) {
+	c.expireAt = deadline(c.now(), c.jitter.apply(d))
+	if c.sliding {
+		c.expireAt = capDeadline(c.expireAt, c.created, c.maxLife)
 	}
-
-	c.t = time.AfterFunc(d, c.resetData)
 }
 
 // StopTimer stops the timer of the cache.
 //
 //	The data will be retained permanently.
 func (c *Cache[T]) StopTimer() {
-	if c.t != nil {
-		c.t.Stop()
+	c.stopTimer()
+	c.events.flush()
+}
+
+// stopTimer clears the expiry and records a CacheStopped event.
+func (c *Cache[T]) stopTimer() {
+	c.expireIfDue()
+	c.expireAt = time.Time{}
+	if c.ok {
+		c.events.record(CacheStopped, c.data, c.now())
+	}
+}
+
+// expireIfDue resets the data if its deadline has passed.
+func (c *Cache[T]) expireIfDue() {
+	if c.ok && expired(c.expireAt, c.now()) {
+		c.resetData()
 	}
 }
 
+// DeleteExpired resets the data if it has expired.
+//
+//	It implements Expirer so the cache can be registered with a Janitor.
+func (c *Cache[T]) DeleteExpired() {
+	c.expireIfDue()
+	c.events.flush()
+}
+
 // resetData resets the data of the cache.
 func (c *Cache[T]) resetData() {
+	if c.ok {
+		c.events.record(CacheExpired, c.data, c.now())
+		c.stats.expirations.Add(1)
+		c.version++
+	}
 	c.data = *new(T)
+	c.ok = false
+	c.expireAt = time.Time{}
 }
 
 // ResetTimer resets the timer of the cache.
 func (c *Cache[T]) ResetTimer() {
-	c.setTimer()
+	c.resetTimer()
+	c.events.flush()
+}
+
+// resetTimer restarts the duration of data that has not expired yet.
+func (c *Cache[T]) resetTimer() {
+	c.expireIfDue()
+	if c.ok {
+		c.setTimer()
+	}
 }
 
 // SetDuration sets the duration of the cache.
+//
+//	If d is 0, data set afterwards never expires. If d is negative, it expires at once.
 func (c *Cache[T]) SetDuration(d time.Duration) {
 	c.d = d
 }
 
 // Set sets the data of the cache.
 func (c *Cache[T]) Set(data T) {
+	c.set(data)
+	c.events.flush()
+}
+
+// set sets the data and records a CacheReplaced event if there was data before.
+func (c *Cache[T]) set(data T) {
+	c.expireIfDue()
+	if c.ok {
+		c.events.record(CacheReplaced, c.data, c.now())
+	}
 	c.data = data
+	c.ok = true
+	c.created = c.now()
+	c.version++
 	c.setTimer()
+	c.stats.sets.Add(1)
+}
+
+// AddListener registers l to be called when the data expires, is replaced or its timer is stopped.
+//
+//	Expiry is reported when it is detected, by the next access or by DeleteExpired.
+func (c *Cache[T]) AddListener(l CacheListener[T]) {
+	c.events.add(l)
 }
 
+// Get gets the data of the cache.
 func (c *Cache[T]) Get() T {
+	data := c.lookup()
+	c.events.flush()
+	return data
+}
+
+// get returns the data after resetting it if it has expired.
+func (c *Cache[T]) get() T {
+	c.expireIfDue()
 	return c.data
 }
 
+// lookup is get for callers of Get, recording a hit or a miss.
+func (c *Cache[T]) lookup() T {
+	data := c.get()
+	c.stats.lookup(c.ok)
+	if c.ok && c.sliding && !c.expireAt.IsZero() {
+		c.setTimer()
+	}
+	return data
+}
+
+// Stats returns a snapshot of the counters of the cache.
+func (c *Cache[T]) Stats() CacheStats {
+	return c.stats.snapshot()
+}
+
 // NewCache creates a new cache.
 //
-//	If d is 0, the cache will never expire.
+//	If d is 0, the cache will never expire. If d is negative, data expires at once.
 func NewCache[T any](d time.Duration, data ...T) *Cache[T] {
-	c := &Cache[T]{}
-	c.resetData()
-	c.SetDuration(d)
+	c := NewCacheWithOptions[T](d)
 	if len(data) > 0 {
 		c.Set(data[0])
 	}
 	return c
 }
+
+// NewCacheWithOptions creates a new empty cache configured by opts, such as WithClock or WithJitter.
+//
+//	If d is 0, the cache will never expire. If d is negative, data expires at once.
+//	Register listeners with AddListener.
+func NewCacheWithOptions[T any](d time.Duration, opts ...CacheOption) *Cache[T] {
+	c := &Cache[T]{}
+	c.init(d, newCacheOptions(opts))
+	return c
+}
+
+// init applies the duration and options to an empty cache.
+func (c *Cache[T]) init(d time.Duration, o cacheOptions) {
+	c.clock = o.clock
+	c.sliding = o.sliding
+	c.maxLife = o.maxLife
+	c.jitter = o.jitter
+	c.SetDuration(d)
+}
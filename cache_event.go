@@ -0,0 +1,101 @@
+package wtype
+
+import "time"
+
+// CacheEventReason describes why a cache value left the cache or stopped expiring.
+type CacheEventReason int
+
+const (
+	// CacheExpired means the value was reset because its duration elapsed.
+	CacheExpired CacheEventReason = iota + 1
+	// CacheReplaced means the value was overwritten by Set.
+	CacheReplaced
+	// CacheStopped means StopTimer was called and the value will be retained.
+	CacheStopped
+)
+
+// String returns the name of the reason.
+func (r CacheEventReason) String() string {
+	switch r {
+	case CacheExpired:
+		return "expired"
+	case CacheReplaced:
+		return "replaced"
+	case CacheStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// CacheEvent is passed to cache listeners.
+type CacheEvent[T any] struct {
+	Reason CacheEventReason
+	Old    T
+	Time   time.Time
+}
+
+// CacheListener receives cache events.
+//
+//	Listeners are called after the cache has released its lock, so they may use the cache.
+type CacheListener[T any] func(CacheEvent[T])
+
+// CacheEventPublisher returns a listener that emits every event to ec under key.
+//
+//	Handlers registered on ec receive the CacheEvent as their only argument.
+func CacheEventPublisher[T any](ec *EventCenter, key string) CacheListener[T] {
+	return func(e CacheEvent[T]) {
+		ec.Emit(key, e)
+	}
+}
+
+// cacheEvents records events until they can be delivered.
+type cacheEvents[T any] struct {
+	listeners []CacheListener[T]
+	pending   []CacheEvent[T]
+}
+
+// add registers a listener.
+func (c *cacheEvents[T]) add(l CacheListener[T]) {
+	if l != nil {
+		c.listeners = append(c.listeners, l)
+	}
+}
+
+// record queues an event that happened at now if anyone is listening.
+func (c *cacheEvents[T]) record(reason CacheEventReason, old T, now time.Time) {
+	if len(c.listeners) == 0 {
+		return
+	}
+	c.pending = append(c.pending, CacheEvent[T]{
+		Reason: reason,
+		Old:    old,
+		Time:   now,
+	})
+}
+
+// take removes the queued events and returns them with the listeners to call.
+func (c *cacheEvents[T]) take() ([]CacheEvent[T], []CacheListener[T]) {
+	if len(c.pending) == 0 {
+		return nil, nil
+	}
+	events := c.pending
+	c.pending = nil
+	listeners := make([]CacheListener[T], len(c.listeners))
+	copy(listeners, c.listeners)
+	return events, listeners
+}
+
+// dispatchCacheEvents calls every listener for every event in order.
+func dispatchCacheEvents[T any](events []CacheEvent[T], listeners []CacheListener[T]) {
+	for _, e := range events {
+		for _, l := range listeners {
+			l(e)
+		}
+	}
+}
+
+// flush delivers the queued events.
+func (c *cacheEvents[T]) flush() {
+	dispatchCacheEvents(c.take())
+}
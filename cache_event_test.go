@@ -0,0 +1,182 @@
+package wtype_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+// eventRecorder collects cache events for assertions.
+type eventRecorder[T any] struct {
+	mu     sync.Mutex
+	events []wtype.CacheEvent[T]
+}
+
+func (r *eventRecorder[T]) listen(e wtype.CacheEvent[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder[T]) get() []wtype.CacheEvent[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]wtype.CacheEvent[T](nil), r.events...)
+}
+
+func TestCache_Listener(t *testing.T) {
+	r := &eventRecorder[int]{}
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+	c.AddListener(r.listen)
+
+	c.Set(1)
+	c.Set(2)
+	clock.Advance(time.Minute)
+	c.Set(3)
+	c.StopTimer()
+
+	events := r.get()
+	want := []struct {
+		reason wtype.CacheEventReason
+		old    int
+	}{
+		{wtype.CacheReplaced, 1},
+		{wtype.CacheExpired, 2},
+		{wtype.CacheStopped, 3},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Reason != w.reason || events[i].Old != w.old {
+			t.Errorf("event %d: expected %v/%d, got %v/%d", i, w.reason, w.old, events[i].Reason, events[i].Old)
+		}
+		if events[i].Time.IsZero() {
+			t.Errorf("event %d: expected a timestamp", i)
+		}
+	}
+}
+
+func TestSafeCache_Listener(t *testing.T) {
+	t.Run("initial data expires", func(t *testing.T) {
+		r := &eventRecorder[string]{}
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewSafeCacheWithOptions[string](time.Minute, wtype.WithClock(clock))
+		c.Set("init")
+		c.AddListener(r.listen)
+
+		clock.Advance(time.Minute)
+		if c.Get() != "" {
+			t.Errorf("expected data to be reset, got %q", c.Get())
+		}
+		events := r.get()
+		if len(events) != 1 || events[0].Reason != wtype.CacheExpired || events[0].Old != "init" {
+			t.Errorf("expected one expired event, got %+v", events)
+		}
+	})
+
+	t.Run("listener may use the cache", func(t *testing.T) {
+		c := wtype.NewSafeCache[int](time.Second)
+		var seen int
+		c.AddListener(func(e wtype.CacheEvent[int]) {
+			seen = c.Get()
+		})
+		c.Set(1)
+		c.Use(func(i int) int { return i + 1 })
+		if seen != 2 {
+			t.Errorf("expected listener to see 2, got %d", seen)
+		}
+		c.StopTimer()
+	})
+}
+
+func TestCustomCache_Listener(t *testing.T) {
+	var data int
+	c := wtype.NewCustomCache[int](
+		time.Second,
+		func(i int, d time.Duration) { data = i },
+		func() int { return data },
+		nil,
+		nil,
+		func() {},
+	)
+	r := &eventRecorder[int]{}
+	c.AddListener(r.listen)
+
+	c.Set(1)
+	c.Set(2)
+	c.StopTimer()
+	c.NotifyExpired(2)
+
+	events := r.get()
+	// the first Set replaces nothing
+	reasons := []wtype.CacheEventReason{wtype.CacheReplaced, wtype.CacheStopped, wtype.CacheExpired}
+	if len(events) != len(reasons) {
+		t.Fatalf("expected %d events, got %+v", len(reasons), events)
+	}
+	for i, reason := range reasons {
+		if events[i].Reason != reason {
+			t.Errorf("event %d: expected %v, got %v", i, reason, events[i].Reason)
+		}
+	}
+	if events[0].Old != 1 {
+		t.Errorf("expected replaced value 1, got %d", events[0].Old)
+	}
+}
+
+func TestCacheEventPublisher(t *testing.T) {
+	ec := wtype.NewEventCenter()
+	var got wtype.CacheEvent[int]
+	ec.On("cache", func(data ...any) {
+		got = data[0].(wtype.CacheEvent[int])
+	})
+
+	c := wtype.NewCache[int](0, 1)
+	c.AddListener(wtype.CacheEventPublisher[int](ec, "cache"))
+	c.Set(2)
+
+	if got.Reason != wtype.CacheReplaced || got.Old != 1 {
+		t.Errorf("expected replaced event with 1, got %+v", got)
+	}
+	if got.Reason.String() != "replaced" {
+		t.Errorf("expected reason name, got %q", got.Reason.String())
+	}
+}
+
+func TestCustomCache_ListenerNoData(t *testing.T) {
+	var data any
+	c := wtype.NewCustomCacheWithOptions[any](time.Second,
+		wtype.WithSetFunc(func(v any, _ time.Duration) { data = v }),
+		wtype.WithGetFunc(func() any { return data }),
+	)
+	r := &eventRecorder[any]{}
+	c.AddListener(r.listen)
+
+	c.StopTimer()
+	c.Set("a")
+	if events := r.get(); len(events) != 0 {
+		t.Errorf("expected no events without data, got %+v", events)
+	}
+}
+
+func TestCustomCache_NoListenersSkipGet(t *testing.T) {
+	var data, gets int
+	c := wtype.NewCustomCache[int](
+		time.Second,
+		func(i int, d time.Duration) { data = i },
+		func() int { gets++; return data },
+		nil,
+		nil,
+		func() {},
+	)
+
+	c.Set(1)
+	c.Set(2)
+	c.StopTimer()
+	if gets != 0 {
+		t.Errorf("expected no get calls without listeners, got %d", gets)
+	}
+}
@@ -128,3 +128,55 @@ func ExampleCache_ResetTimer() {
 	// Timer reset, data: "data"
 	// After partial wait, data: "data"
 }
+
+// ExampleCache_AddListener demonstrates observing replaced and expired data
+func ExampleCache_AddListener() {
+	cache := wtype.NewCache[string](50 * time.Millisecond)
+	cache.AddListener(func(e wtype.CacheEvent[string]) {
+		fmt.Printf("%s: %q\n", e.Reason, e.Old)
+	})
+
+	cache.Set("first")
+	cache.Set("second")
+	time.Sleep(100 * time.Millisecond)
+	cache.Get() // expiry is detected on access
+
+	// Output:
+	// replaced: "first"
+	// expired: "second"
+}
+
+// ExampleNewFakeClock demonstrates testing expiry without sleeping
+func ExampleNewFakeClock() {
+	clock := wtype.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := wtype.NewCacheWithOptions[string](time.Hour, wtype.WithClock(clock))
+	cache.Set("data")
+
+	clock.Advance(59 * time.Minute)
+	fmt.Printf("%q\n", cache.Get())
+
+	clock.Advance(time.Minute)
+	fmt.Printf("%q\n", cache.Get())
+
+	// Output:
+	// "data"
+	// ""
+}
+
+func ExampleNewCustomCacheWithOptions() {
+	var data string
+	c := wtype.NewCustomCacheWithOptions[string](time.Minute,
+		wtype.WithSetFunc(func(v string, d time.Duration) {
+			data = v
+			fmt.Println("set", v, "for", d)
+		}),
+		wtype.WithGetFunc(func() string { return data }),
+	)
+
+	c.Set("hello")
+	fmt.Println(c.Get())
+
+	// Output:
+	// set hello for 1m0s
+	// hello
+}
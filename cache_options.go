@@ -0,0 +1,304 @@
+package wtype
+
+import "time"
+
+// cacheOptions holds the settings collected from options.
+type cacheOptions struct {
+	capacity int
+	policy   EvictionPolicy
+	clock    Clock
+	sliding  bool
+	maxLife  time.Duration
+	jitter   jitter
+	duration time.Duration
+	negative time.Duration
+	batch    int
+	interval time.Duration
+	onError  func(error)
+	shards   int
+}
+
+// option changes the settings; every option type is built on it.
+type option func(*cacheOptions)
+
+func (f option) apply(o *cacheOptions) {
+	if f != nil {
+		f(o)
+	}
+}
+
+// newCacheOptions applies opts on top of the default settings.
+func newCacheOptions[O interface{ apply(*cacheOptions) }](opts []O) cacheOptions {
+	o := cacheOptions{clock: RealClock, batch: 100, interval: time.Second, shards: 16}
+	for _, opt := range opts {
+		if any(opt) != nil {
+			opt.apply(&o)
+		}
+	}
+	return o
+}
+
+// Each kind of constructor takes its own option interface, implemented only by the options that apply to it,
+// so passing an option to a constructor that would ignore it does not compile.
+type (
+	// CacheOption configures a Cache or SafeCache when it is created.
+	CacheOption interface {
+		apply(*cacheOptions)
+		cacheOption()
+	}
+
+	// KeyedCacheOption configures a KeyedCache when it is created, or the KeyedCache inside a TieredCache or MemoryStore.
+	KeyedCacheOption interface {
+		apply(*cacheOptions)
+		keyedCacheOption()
+	}
+
+	// ShardedCacheOption configures a ShardedCache when it is created.
+	//
+	//	Every KeyedCacheOption is also a ShardedCacheOption.
+	ShardedCacheOption interface {
+		apply(*cacheOptions)
+		shardedCacheOption()
+	}
+
+	// LoadingCacheOption configures a LoadingCache when it is created.
+	//
+	//	Every KeyedCacheOption is also a LoadingCacheOption.
+	LoadingCacheOption interface {
+		apply(*cacheOptions)
+		loadingCacheOption()
+	}
+
+	// MemoizeOption configures Memoize.
+	//
+	//	Every LoadingCacheOption is also a MemoizeOption.
+	MemoizeOption interface {
+		apply(*cacheOptions)
+		memoizeOption()
+	}
+
+	// WriteBehindOption configures a WriteBehindCache when it is created.
+	WriteBehindOption interface {
+		apply(*cacheOptions)
+		writeBehindOption()
+	}
+)
+
+// ClockOption is returned by WithClock.
+//
+//	It applies to every constructor that measures time, including NewJanitor and NewFileStore.
+type ClockOption struct{ option }
+
+func (ClockOption) cacheOption()        {}
+func (ClockOption) keyedCacheOption()   {}
+func (ClockOption) shardedCacheOption() {}
+func (ClockOption) loadingCacheOption() {}
+func (ClockOption) memoizeOption()      {}
+func (ClockOption) writeBehindOption()  {}
+
+// ExpiryOption is returned by WithSliding, WithJitter and WithJitterRange.
+//
+//	It applies to Cache, SafeCache and the keyed caches.
+type ExpiryOption struct{ option }
+
+func (ExpiryOption) cacheOption()        {}
+func (ExpiryOption) keyedCacheOption()   {}
+func (ExpiryOption) shardedCacheOption() {}
+func (ExpiryOption) loadingCacheOption() {}
+func (ExpiryOption) memoizeOption()      {}
+
+// EvictionOption is returned by WithCapacity and WithEvictionPolicy.
+//
+//	It applies to the keyed caches.
+type EvictionOption struct{ option }
+
+func (EvictionOption) keyedCacheOption()   {}
+func (EvictionOption) shardedCacheOption() {}
+func (EvictionOption) loadingCacheOption() {}
+func (EvictionOption) memoizeOption()      {}
+
+// ShardOption is returned by WithShards and applies to NewShardedCache.
+type ShardOption struct{ option }
+
+func (ShardOption) shardedCacheOption() {}
+
+// NegativeDurationOption is returned by WithNegativeDuration and applies to NewLoadingCache and Memoize.
+type NegativeDurationOption struct{ option }
+
+func (NegativeDurationOption) loadingCacheOption() {}
+func (NegativeDurationOption) memoizeOption()      {}
+
+// DurationOption is returned by WithDuration and applies to Memoize.
+type DurationOption struct{ option }
+
+func (DurationOption) memoizeOption() {}
+
+// BatchOption is returned by WithBatchSize and WithFlushInterval and applies to NewWriteBehindCache.
+type BatchOption struct{ option }
+
+func (BatchOption) writeBehindOption() {}
+
+// ErrorHandlerOption is returned by WithErrorHandler and applies to NewWriteThroughCache and NewWriteBehindCache.
+type ErrorHandlerOption struct{ option }
+
+func (ErrorHandlerOption) writeBehindOption() {}
+
+// WithCapacity limits the number of entries a keyed cache can hold.
+//
+//	When a new key is added to a full cache, an entry is evicted according to the eviction policy.
+//	If n is 0, the cache is unbounded.
+func WithCapacity(n int) EvictionOption {
+	return EvictionOption{func(o *cacheOptions) {
+		o.capacity = n
+	}}
+}
+
+// WithEvictionPolicy sets the policy used to pick an entry to evict.
+//
+//	The default policy is EvictLRU.
+func WithEvictionPolicy(p EvictionPolicy) EvictionOption {
+	return EvictionOption{func(o *cacheOptions) {
+		o.policy = p
+	}}
+}
+
+// WithClock sets the clock used to compute expiry.
+//
+//	Pass a FakeClock in tests to expire data without sleeping.
+func WithClock(c Clock) ClockOption {
+	return ClockOption{func(o *cacheOptions) {
+		o.clock = clockOrReal(c)
+	}}
+}
+
+// WithSliding enables sliding expiration: every successful Get restarts the duration of the data.
+//
+//	maxLifetime is an absolute limit measured from the last Set that sliding can never go past.
+//	If maxLifetime <= 0, data that keeps being read never expires.
+func WithSliding(maxLifetime time.Duration) ExpiryOption {
+	return ExpiryOption{func(o *cacheOptions) {
+		o.sliding = true
+		o.maxLife = maxLifetime
+	}}
+}
+
+// WithJitter spreads every expiry by up to fraction of the duration in either direction.
+//
+//	For example, 0.1 makes data with a one minute duration expire between 54 and 66 seconds,
+//	so caches created together do not all expire at the same instant.
+func WithJitter(fraction float64) ExpiryOption {
+	return ExpiryOption{func(o *cacheOptions) {
+		o.jitter.fraction = max(fraction, 0)
+	}}
+}
+
+// WithJitterRange spreads every expiry by up to d in either direction.
+//
+//	It can be combined with WithJitter, in which case the spreads are added.
+func WithJitterRange(d time.Duration) ExpiryOption {
+	return ExpiryOption{func(o *cacheOptions) {
+		o.jitter.spread = max(d, 0)
+	}}
+}
+
+// WithDuration sets the duration of the results of Memoize.
+//
+//	If d is 0, values never expire.
+func WithDuration(d time.Duration) DurationOption {
+	return DurationOption{func(o *cacheOptions) {
+		o.duration = d
+	}}
+}
+
+// WithNegativeDuration caches errors for d, so a failing function is not called on every lookup.
+//
+//	It applies to NewLoadingCache and Memoize. If d <= 0, errors are not cached.
+func WithNegativeDuration(d time.Duration) NegativeDurationOption {
+	return NegativeDurationOption{func(o *cacheOptions) {
+		o.negative = d
+	}}
+}
+
+// WithBatchSize sets how many values a write-behind cache queues before it writes them to its sink.
+//
+//	The default is 100.
+func WithBatchSize(n int) BatchOption {
+	return BatchOption{func(o *cacheOptions) {
+		o.batch = n
+	}}
+}
+
+// WithFlushInterval sets how long a write-behind cache waits before writing queued values to its sink.
+//
+//	The default is one second. If d <= 0, values are only written when a batch is full, or by Flush or Close.
+func WithFlushInterval(d time.Duration) BatchOption {
+	return BatchOption{func(o *cacheOptions) {
+		o.interval = d
+	}}
+}
+
+// WithErrorHandler sets the function called when a sink of a write-through or write-behind cache
+// fails and there is no caller to return the error to.
+func WithErrorHandler(f func(error)) ErrorHandlerOption {
+	return ErrorHandlerOption{func(o *cacheOptions) {
+		o.onError = f
+	}}
+}
+
+// WithShards sets the number of independently locked shards of a ShardedCache.
+//
+//	The default is 16. More shards reduce lock contention at the cost of memory.
+func WithShards(n int) ShardOption {
+	return ShardOption{func(o *cacheOptions) {
+		o.shards = n
+	}}
+}
+
+// CustomCacheOption configures a CustomCache of T when it is created.
+//
+//	Options whose arguments do not mention T, such as WithStopTimerFunc, need T given explicitly.
+type CustomCacheOption[T any] func(*CustomCache[T])
+
+// WithSetFunc sets the function a CustomCache calls to store data for a duration.
+func WithSetFunc[T any](f func(T, time.Duration)) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.setFunc = f
+	}
+}
+
+// WithGetFunc sets the function a CustomCache calls to read its data.
+func WithGetFunc[T any](f func() T) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.getFunc = f
+	}
+}
+
+// WithBeforeSetDuration sets the function a CustomCache calls to adjust a duration passed to SetDuration.
+func WithBeforeSetDuration[T any](f func(time.Duration) time.Duration) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.beforeSetDuration = f
+	}
+}
+
+// WithResetTimerFunc sets the function a CustomCache calls with its duration on ResetTimer.
+func WithResetTimerFunc[T any](f func(time.Duration)) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.resetTimer = f
+	}
+}
+
+// WithStopTimerFunc sets the function a CustomCache calls on StopTimer.
+func WithStopTimerFunc[T any](f func()) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.stopTimer = f
+	}
+}
+
+// WithListener registers l on a CustomCache when it is created, as AddListener does.
+//
+//	Cache and SafeCache take options that do not depend on T, so register their listeners with AddListener.
+func WithListener[T any](l CacheListener[T]) CustomCacheOption[T] {
+	return func(c *CustomCache[T]) {
+		c.events.add(l)
+	}
+}
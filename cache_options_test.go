@@ -0,0 +1,205 @@
+package wtype_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestCache_Sliding(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+
+	t.Run("Get extends the lifetime", func(t *testing.T) {
+		c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock), wtype.WithSliding(0))
+		c.Set(1)
+		for i := 0; i < 5; i++ {
+			clock.Advance(50 * time.Second)
+			if c.Get() != 1 {
+				t.Fatalf("read %d: data should still exist", i)
+			}
+		}
+		clock.Advance(time.Minute)
+		if c.Get() != 0 {
+			t.Error("data should expire once it is no longer read")
+		}
+	})
+
+	t.Run("maximum lifetime caps sliding", func(t *testing.T) {
+		c := wtype.NewCacheWithOptions[int](time.Minute, wtype.WithClock(clock), wtype.WithSliding(2*time.Minute))
+		c.Set(1)
+		clock.Advance(50 * time.Second)
+		c.Get()
+		clock.Advance(50 * time.Second)
+		c.Get()
+		c.ResetTimer()
+		clock.Advance(20 * time.Second)
+		if c.Get() != 0 {
+			t.Error("data should expire at the maximum lifetime")
+		}
+
+		c.Set(2) // a new Set restarts the maximum lifetime
+		clock.Advance(50 * time.Second)
+		if c.Get() != 2 {
+			t.Error("data should exist after a new Set")
+		}
+	})
+
+	t.Run("KeyedCache", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock), wtype.WithSliding(90*time.Second))
+		c.Set("a", 1)
+		clock.Advance(50 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("a should exist")
+		}
+		clock.Advance(30 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("a should have been extended")
+		}
+		clock.Advance(10 * time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Error("a should expire at the maximum lifetime")
+		}
+	})
+
+	t.Run("without sliding Get does not extend", func(t *testing.T) {
+		c := wtype.NewCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.Set(1)
+		clock.Advance(50 * time.Second)
+		c.Get()
+		clock.Advance(10 * time.Second)
+		if c.Get() != 0 {
+			t.Error("data should expire")
+		}
+	})
+}
+
+func TestCache_Jitter(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+
+	caches := make([]*wtype.SafeCache[int], 200)
+	for i := range caches {
+		caches[i] = wtype.NewSafeCacheWithOptions[int](100*time.Second, wtype.WithClock(clock), wtype.WithJitter(0.2))
+		caches[i].Set(1)
+	}
+	keyed := wtype.NewKeyedCache[int, int](100*time.Second, wtype.WithClock(clock), wtype.WithJitterRange(20*time.Second))
+	for i := 0; i < 200; i++ {
+		keyed.Set(i, i)
+	}
+
+	countAlive := func() int {
+		n := 0
+		for _, c := range caches {
+			if c.Get() != 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	clock.Advance(80*time.Second - time.Nanosecond)
+	if n := countAlive(); n != len(caches) {
+		t.Fatalf("no cache should expire before the jitter window, %d alive", n)
+	}
+	if keyed.Len() != 200 {
+		t.Fatalf("no entry should expire before the jitter window, %d alive", keyed.Len())
+	}
+
+	clock.Advance(20 * time.Second)
+	if n := countAlive(); n == 0 || n == len(caches) {
+		t.Errorf("expected caches to expire at different times, %d alive", n)
+	}
+	if n := keyed.Len(); n == 0 || n == 200 {
+		t.Errorf("expected entries to expire at different times, %d alive", n)
+	}
+
+	clock.Advance(20 * time.Second)
+	if n := countAlive(); n != 0 {
+		t.Errorf("every cache should expire after the jitter window, %d alive", n)
+	}
+	if keyed.Len() != 0 {
+		t.Errorf("every entry should expire after the jitter window, %d alive", keyed.Len())
+	}
+}
+
+func TestCache_JitterNeverExpire(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewCacheWithOptions[int](0, wtype.WithClock(clock), wtype.WithJitterRange(time.Second))
+	c.Set(1)
+	clock.Advance(time.Hour)
+	if c.Get() != 1 {
+		t.Error("jitter must not make a cache without duration expire")
+	}
+}
+
+func TestNewCustomCacheWithOptions(t *testing.T) {
+	var (
+		stored   int
+		duration time.Duration
+		stopped  bool
+		events   []wtype.CacheEventReason
+	)
+	c := wtype.NewCustomCacheWithOptions[int](time.Second,
+		wtype.WithSetFunc(func(v int, d time.Duration) {
+			stored, duration = v, d
+		}),
+		wtype.WithGetFunc(func() int { return stored }),
+		wtype.WithBeforeSetDuration[int](func(d time.Duration) time.Duration { return 2 * d }),
+		wtype.WithStopTimerFunc[int](func() { stopped = true }),
+		wtype.WithListener(func(e wtype.CacheEvent[int]) {
+			events = append(events, e.Reason)
+		}),
+	)
+
+	c.Set(1)
+	if c.Get() != 1 || duration != time.Second {
+		t.Errorf("expected (1, 1s), got (%d, %v)", c.Get(), duration)
+	}
+	c.SetDuration(time.Minute)
+	c.Set(2)
+	if duration != 2*time.Minute {
+		t.Errorf("expected the duration to be adjusted to 2m, got %v", duration)
+	}
+	c.ResetTimer() // no function given, does nothing
+	c.StopTimer()
+	if !stopped {
+		t.Error("expected the stop function to be called")
+	}
+	want := []wtype.CacheEventReason{wtype.CacheReplaced, wtype.CacheStopped}
+	if len(events) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: expected %v, got %v", i, want[i], events[i])
+		}
+	}
+}
+
+func TestCache_NegativeDurationExpiresAtOnce(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+
+	c := wtype.NewSafeCacheWithOptions[int](-time.Second, wtype.WithClock(clock))
+	c.Set(1)
+	if c.Get() != 0 {
+		t.Error("expected a negative duration to expire at once")
+	}
+
+	c.SetDuration(0)
+	c.Set(2)
+	clock.Advance(time.Hour)
+	if c.Get() != 2 {
+		t.Error("expected a zero duration to never expire")
+	}
+
+	k := wtype.NewKeyedCache[string, int](time.Second, wtype.WithClock(clock))
+	k.SetWithDuration("a", 1, -time.Second)
+	if _, ok := k.Get("a"); ok {
+		t.Error("expected a negative entry duration to expire at once")
+	}
+	k.SetWithDuration("b", 2, 0)
+	clock.Advance(time.Hour)
+	if ttl, ok := k.TTL("b"); !ok || ttl != 0 {
+		t.Errorf("expected a zero entry duration to never expire, got (%v, %v)", ttl, ok)
+	}
+}
@@ -0,0 +1,99 @@
+package wtype
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats is a snapshot of the counters of a cache.
+type CacheStats struct {
+	Hits        uint64        // Get calls that found data
+	Misses      uint64        // Get calls that found no data
+	Sets        uint64        // data stored by Set, Use or a load
+	Expirations uint64        // data removed because its duration elapsed
+	Evictions   uint64        // data removed to respect the capacity
+	Loads       uint64        // loader or refresh calls
+	LoadErrors  uint64        // loader or refresh calls that returned an error
+	LoadTime    time.Duration // total time spent in loader or refresh calls
+}
+
+// add returns the sum of the counters of s and o.
+func (s CacheStats) add(o CacheStats) CacheStats {
+	return CacheStats{
+		Hits:        s.Hits + o.Hits,
+		Misses:      s.Misses + o.Misses,
+		Sets:        s.Sets + o.Sets,
+		Expirations: s.Expirations + o.Expirations,
+		Evictions:   s.Evictions + o.Evictions,
+		Loads:       s.Loads + o.Loads,
+		LoadErrors:  s.LoadErrors + o.LoadErrors,
+		LoadTime:    s.LoadTime + o.LoadTime,
+	}
+}
+
+// Requests returns the number of Get calls.
+func (s CacheStats) Requests() uint64 {
+	return s.Hits + s.Misses
+}
+
+// HitRate returns the ratio of hits to requests, or 0 if there were no requests.
+func (s CacheStats) HitRate() float64 {
+	if s.Requests() == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Requests())
+}
+
+// AverageLoadTime returns the mean duration of a load, or 0 if nothing was loaded.
+func (s CacheStats) AverageLoadTime() time.Duration {
+	if s.Loads == 0 {
+		return 0
+	}
+	return s.LoadTime / time.Duration(s.Loads)
+}
+
+// cacheStats holds the counters of a cache.
+//
+//	All fields are atomic so recording never takes a lock.
+type cacheStats struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	sets        atomic.Uint64
+	expirations atomic.Uint64
+	evictions   atomic.Uint64
+	loads       atomic.Uint64
+	loadErrors  atomic.Uint64
+	loadTime    atomic.Int64
+}
+
+// lookup records a hit or a miss.
+func (s *cacheStats) lookup(hit bool) {
+	if hit {
+		s.hits.Add(1)
+	} else {
+		s.misses.Add(1)
+	}
+}
+
+// load records a loader call that took d.
+func (s *cacheStats) load(d time.Duration, err error) {
+	s.loads.Add(1)
+	s.loadTime.Add(int64(d))
+	if err != nil {
+		s.loadErrors.Add(1)
+	}
+}
+
+// snapshot returns the current values of the counters.
+func (s *cacheStats) snapshot() CacheStats {
+	return CacheStats{
+		Hits:        s.hits.Load(),
+		Misses:      s.misses.Load(),
+		Sets:        s.sets.Load(),
+		Expirations: s.expirations.Load(),
+		Evictions:   s.evictions.Load(),
+		Loads:       s.loads.Load(),
+		LoadErrors:  s.loadErrors.Load(),
+		LoadTime:    time.Duration(s.loadTime.Load()),
+	}
+}
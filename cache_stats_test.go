@@ -0,0 +1,86 @@
+package wtype_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestSafeCache_Stats(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+
+	c.Get()
+	c.Set(1)
+	c.Get()
+	c.Get()
+	c.Use(func(i int) int { return i + 1 })
+	clock.Advance(time.Minute)
+	c.Get()
+
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 2 || s.Sets != 2 || s.Expirations != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s.Requests() != 4 || s.HitRate() != 0.5 {
+		t.Errorf("expected 4 requests with hit rate 0.5, got %d and %v", s.Requests(), s.HitRate())
+	}
+}
+
+func TestKeyedCache_Stats(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewKeyedCache[int, int](time.Minute, wtype.WithClock(clock), wtype.WithCapacity(2))
+
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3) // evicts 1
+	c.Get(1)
+	c.Get(2)
+	clock.Advance(time.Minute)
+	c.DeleteExpired()
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Sets != 3 || s.Evictions != 1 || s.Expirations != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	c.Set(4, 4)
+	c.Delete(4) // explicit deletes are not evictions
+	if s := c.Stats(); s.Evictions != 1 {
+		t.Errorf("expected Delete not to count as an eviction, got %d", s.Evictions)
+	}
+}
+
+func TestLoadingCache_Stats(t *testing.T) {
+	errFailed := errors.New("failed")
+	c := wtype.NewLoadingCache(time.Minute, func(ctx context.Context, key int) (int, error) {
+		time.Sleep(time.Millisecond)
+		if key < 0 {
+			return 0, errFailed
+		}
+		return key, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _ = c.Get(context.Background(), 1)
+	}
+	_, _ = c.Get(context.Background(), -1)
+
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 2 || s.Loads != 2 || s.LoadErrors != 1 || s.Sets != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s.AverageLoadTime() < time.Millisecond {
+		t.Errorf("expected load time to be recorded, got %v", s.AverageLoadTime())
+	}
+}
+
+func TestCacheStats_Empty(t *testing.T) {
+	var s wtype.CacheStats
+	if s.HitRate() != 0 || s.AverageLoadTime() != 0 {
+		t.Error("empty stats should not divide by zero")
+	}
+}
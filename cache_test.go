@@ -211,8 +211,8 @@ func TestCache_ConcurrentAccess(t *testing.T) {
 func TestCache_EdgeCases(t *testing.T) {
 	t.Run("negative duration treated as immediate", func(t *testing.T) {
 		c := wtype.NewCache[int](-time.Second, 42)
-		if c.Get() == 0 {
-			t.Error("cache should not expire with negative duration")
+		if c.Get() != 0 {
+			t.Error("cache should expire at once with negative duration")
 		}
 	})
 
@@ -0,0 +1,151 @@
+package wtype
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by caches and janitors.
+//
+//	Use RealClock in production and a FakeClock in tests to control expiry without sleeping.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer is a timer created by Clock.AfterFunc.
+type ClockTimer interface {
+	// Stop prevents the timer from firing.
+	// It returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+// RealClock is the Clock backed by the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
+
+// clockOrReal returns c, or RealClock if c is nil.
+func clockOrReal(c Clock) Clock {
+	if c == nil {
+		return RealClock
+	}
+	return c
+}
+
+// FakeClock is a Clock that only moves when Advance or Set is called.
+//
+//	Timers created by AfterFunc run synchronously inside Advance or Set, in order of their deadlines.
+//	It is safe for concurrent use.
+type FakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	seq    uint64
+	mutex  sync.Mutex
+}
+
+// fakeTimer is a timer scheduled on a FakeClock.
+type fakeTimer struct {
+	c   *FakeClock
+	at  time.Time
+	f   func()
+	seq uint64 // keeps timers with the same deadline in creation order
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// AfterFunc calls f once the fake time has advanced by d.
+//
+//	If d <= 0, f is called the next time the clock moves.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.seq++
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f, seq: c.seq}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Stop removes the timer from its clock.
+func (t *fakeTimer) Stop() bool {
+	t.c.mutex.Lock()
+	defer t.c.mutex.Unlock()
+	for i, other := range t.c.timers {
+		if other == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the fake time forward by d and runs the timers that became due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	target := c.now.Add(d)
+	c.mutex.Unlock()
+	c.Set(target)
+}
+
+// Set moves the fake time to t and runs the timers that became due.
+//
+//	Timers see Now() equal to their own deadline while they run, and timers they create
+//	are run as well if they are due before t.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mutex.Lock()
+		next := c.popDue(t)
+		if next == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mutex.Unlock()
+			return
+		}
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		c.mutex.Unlock()
+		next.f()
+	}
+}
+
+// popDue removes and returns the earliest timer due at or before t.
+//
+//	The caller must hold the lock.
+func (c *FakeClock) popDue(t time.Time) *fakeTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		if c.timers[i].at.Equal(c.timers[j].at) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+	next := c.timers[0]
+	if next.at.After(t) {
+		return nil
+	}
+	c.timers = c.timers[1:]
+	return next
+}
+
+// NewFakeClock creates a fake clock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
@@ -0,0 +1,134 @@
+package wtype_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+var clockStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock(t *testing.T) {
+	c := wtype.NewFakeClock(clockStart)
+	if !c.Now().Equal(clockStart) {
+		t.Fatalf("expected %v, got %v", clockStart, c.Now())
+	}
+
+	var order []int
+	var firedAt time.Time
+	c.AfterFunc(2*time.Second, func() {
+		order = append(order, 2)
+		firedAt = c.Now()
+	})
+	c.AfterFunc(time.Second, func() {
+		order = append(order, 1)
+		c.AfterFunc(500*time.Millisecond, func() { order = append(order, 15) })
+	})
+	stopped := c.AfterFunc(time.Second, func() { order = append(order, -1) })
+	if !stopped.Stop() {
+		t.Error("expected Stop to report a pending timer")
+	}
+	if stopped.Stop() {
+		t.Error("expected second Stop to report false")
+	}
+
+	c.Advance(1500 * time.Millisecond)
+	if len(order) != 2 || order[0] != 1 || order[1] != 15 {
+		t.Errorf("expected [1 15], got %v", order)
+	}
+
+	c.Advance(time.Second)
+	if len(order) != 3 || order[2] != 2 {
+		t.Errorf("expected [1 15 2], got %v", order)
+	}
+	if !firedAt.Equal(clockStart.Add(2 * time.Second)) {
+		t.Errorf("expected timer to observe its deadline, got %v", firedAt)
+	}
+	if !c.Now().Equal(clockStart.Add(2500 * time.Millisecond)) {
+		t.Errorf("unexpected time %v", c.Now())
+	}
+}
+
+func TestCache_FakeClock(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+
+	t.Run("Cache", func(t *testing.T) {
+		c := wtype.NewCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.Set(1)
+		clock.Advance(59 * time.Second)
+		if c.Get() != 1 {
+			t.Error("data should not expire yet")
+		}
+		c.ResetTimer()
+		clock.Advance(59 * time.Second)
+		if c.Get() != 1 {
+			t.Error("timer should have been reset")
+		}
+		clock.Advance(time.Second)
+		if c.Get() != 0 {
+			t.Error("data should have expired")
+		}
+	})
+
+	t.Run("SafeCache", func(t *testing.T) {
+		c := wtype.NewSafeCacheWithOptions[string](time.Hour, wtype.WithClock(clock))
+		var at time.Time
+		c.AddListener(func(e wtype.CacheEvent[string]) { at = e.Time })
+		c.Set("a")
+		clock.Advance(time.Hour)
+		if c.Get() != "" {
+			t.Error("data should have expired")
+		}
+		if !at.Equal(clock.Now()) {
+			t.Errorf("expected event time %v, got %v", clock.Now(), at)
+		}
+	})
+
+	t.Run("KeyedCache", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock))
+		c.Set("a", 1)
+		c.SetWithDuration("b", 2, 2*time.Minute)
+		clock.Advance(time.Minute)
+		if _, ok := c.Get("a"); ok {
+			t.Error("a should have expired")
+		}
+		if _, ok := c.Get("b"); !ok {
+			t.Error("b should not expire yet")
+		}
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.SetRefresh(func(old int) (int, error) { return old + 1, nil }, 10*time.Second, 0)
+		c.Set(1)
+		clock.Advance(50 * time.Second)
+		if c.Get() != 2 {
+			t.Errorf("expected refreshed value 2, got %d", c.Get())
+		}
+		clock.Advance(50 * time.Second)
+		if c.Get() != 3 {
+			t.Errorf("expected refreshed value 3, got %d", c.Get())
+		}
+		c.StopTimer()
+	})
+
+	t.Run("Janitor", func(t *testing.T) {
+		var expiredCount int
+		c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.AddListener(func(e wtype.CacheEvent[int]) { expiredCount++ })
+		c.Set(1)
+
+		j := wtype.NewJanitor(30*time.Second, wtype.WithClock(clock))
+		defer j.Stop()
+		j.Add(c)
+		clock.Advance(30 * time.Second)
+		if expiredCount != 0 {
+			t.Error("data should not expire yet")
+		}
+		clock.Advance(30 * time.Second)
+		if expiredCount != 1 {
+			t.Errorf("expected the janitor to expire the data, got %d events", expiredCount)
+		}
+	})
+}
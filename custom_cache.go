@@ -1,6 +1,7 @@
 package wtype
 
 import (
+	"reflect"
 	"time"
 )
 
@@ -15,8 +16,13 @@ type CustomCache[T any] struct {
 	beforeSetDuration func(duration time.Duration) time.Duration
 	resetTimer        func(time.Duration)
 	stopTimer         func()
+
+	events cacheEvents[T]
 }
 
+// NewCustomCache creates a custom cache from its functions; any of them may be nil.
+//
+//	NewCustomCacheWithOptions is easier to read and cannot mix up functions of the same signature.
 func NewCustomCache[T any](
 	duration time.Duration,
 	setFunc func(T, time.Duration),
@@ -25,21 +31,39 @@ func NewCustomCache[T any](
 	resetTimer func(time.Duration),
 	stopTimer func(),
 ) *CustomCache[T] {
-	return &CustomCache[T]{
-		duration:          duration,
-		setFunc:           setFunc,
-		getFunc:           getFunc,
-		beforeSetDuration: beforeSetDuration,
-		resetTimer:        resetTimer,
-		stopTimer:         stopTimer,
+	return NewCustomCacheWithOptions[T](duration,
+		WithSetFunc(setFunc),
+		WithGetFunc(getFunc),
+		WithBeforeSetDuration[T](beforeSetDuration),
+		WithResetTimerFunc[T](resetTimer),
+		WithStopTimerFunc[T](stopTimer),
+	)
+}
+
+// NewCustomCacheWithOptions creates a custom cache from the functions given by WithSetFunc, WithGetFunc,
+// WithBeforeSetDuration, WithResetTimerFunc and WithStopTimerFunc.
+//
+//	WithListener registers listeners. Functions that are not given do nothing.
+//	The options are typed by T, so a function for another data type does not compile.
+func NewCustomCacheWithOptions[T any](duration time.Duration, opts ...CustomCacheOption[T]) *CustomCache[T] {
+	c := &CustomCache[T]{duration: duration}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
 	}
+	return c
 }
 
 func (c *CustomCache[T]) Set(t T) {
 	if c.setFunc == nil {
 		return
 	}
+	if old, ok := c.current(); ok {
+		c.events.record(CacheReplaced, old, time.Now())
+	}
 	c.setFunc(t, c.duration)
+	c.events.flush()
 }
 
 func (c *CustomCache[T]) Get() T {
@@ -68,4 +92,37 @@ func (c *CustomCache[T]) StopTimer() {
 		return
 	}
 	c.stopTimer()
+	if data, ok := c.current(); ok {
+		c.events.record(CacheStopped, data, time.Now())
+	}
+	c.events.flush()
+}
+
+// current returns the data read with the get function, and whether there is any.
+//
+//	CustomCache cannot tell whether data was set, so the zero value counts as no data,
+//	like the reset data of an expired Cache. The get function is not called when nobody is listening.
+func (c *CustomCache[T]) current() (T, bool) {
+	if c.getFunc == nil || len(c.events.listeners) == 0 {
+		return *new(T), false
+	}
+	data := c.getFunc()
+	return data, !reflect.ValueOf(&data).Elem().IsZero()
+}
+
+// AddListener registers l to be called when the data is replaced, its timer is stopped
+// or NotifyExpired is called.
+//
+//	The replaced value is read with the get function before the set function runs. As with Cache,
+//	no event is sent when there is no data, which for CustomCache means the get function returns the zero value.
+func (c *CustomCache[T]) AddListener(l CacheListener[T]) {
+	c.events.add(l)
+}
+
+// NotifyExpired tells the listeners that old has expired.
+//
+//	CustomCache does not manage expiry itself, so the custom timer should call it when it resets the data.
+func (c *CustomCache[T]) NotifyExpired(old T) {
+	c.events.record(CacheExpired, old, time.Now())
+	c.events.flush()
 }
@@ -0,0 +1,197 @@
+package wtype
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// EvictionPolicy decides which entry leaves a full cache.
+type EvictionPolicy int
+
+const (
+	// EvictLRU evicts the least recently used entry.
+	EvictLRU EvictionPolicy = iota
+	// EvictLFU evicts the least frequently used entry.
+	//
+	//	Entries with the same frequency are evicted in least recently used order.
+	EvictLFU
+)
+
+// String returns the name of the policy.
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictLRU:
+		return "LRU"
+	case EvictLFU:
+		return "LFU"
+	default:
+		return "unknown"
+	}
+}
+
+// evictor tracks key usage and picks the next key to evict.
+//
+//	It is not thread-safe; the owning cache must hold its lock.
+type evictor[K comparable] interface {
+	add(key K)
+	touch(key K)
+	remove(key K)
+	// victim returns the next key to evict, never returning skip.
+	victim(skip K) (K, bool)
+}
+
+// newEvictor creates the evictor for p.
+func newEvictor[K comparable](p EvictionPolicy) evictor[K] {
+	if p == EvictLFU {
+		return newLFU[K]()
+	}
+	return newLRU[K]()
+}
+
+// lru keeps keys in a list ordered from most to least recently used.
+type lru[K comparable] struct {
+	l *list.List
+	m map[K]*list.Element
+}
+
+func newLRU[K comparable]() *lru[K] {
+	return &lru[K]{
+		l: list.New(),
+		m: make(map[K]*list.Element),
+	}
+}
+
+func (e *lru[K]) add(key K) {
+	if el, ok := e.m[key]; ok {
+		e.l.MoveToFront(el)
+		return
+	}
+	e.m[key] = e.l.PushFront(key)
+}
+
+func (e *lru[K]) touch(key K) {
+	if el, ok := e.m[key]; ok {
+		e.l.MoveToFront(el)
+	}
+}
+
+func (e *lru[K]) remove(key K) {
+	if el, ok := e.m[key]; ok {
+		e.l.Remove(el)
+		delete(e.m, key)
+	}
+}
+
+func (e *lru[K]) victim(skip K) (K, bool) {
+	for el := e.l.Back(); el != nil; el = el.Prev() {
+		if key := el.Value.(K); key != skip {
+			return key, true
+		}
+	}
+	return *new(K), false
+}
+
+// lfuItem is a key in the lfu heap.
+type lfuItem[K comparable] struct {
+	key   K
+	freq  uint64
+	tick  uint64 // last access, breaks ties between equal frequencies
+	index int
+}
+
+// lfuHeap is a min-heap ordered by frequency, then by last access.
+type lfuHeap[K comparable] []*lfuItem[K]
+
+func (h lfuHeap[K]) Len() int { return len(h) }
+
+func (h lfuHeap[K]) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[K]) Push(x any) {
+	item := x.(*lfuItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfuHeap[K]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// lfu keeps keys in a heap ordered by access frequency.
+type lfu[K comparable] struct {
+	h    lfuHeap[K]
+	m    map[K]*lfuItem[K]
+	tick uint64
+}
+
+func newLFU[K comparable]() *lfu[K] {
+	return &lfu[K]{
+		m: make(map[K]*lfuItem[K]),
+	}
+}
+
+func (e *lfu[K]) add(key K) {
+	if _, ok := e.m[key]; ok {
+		e.touch(key)
+		return
+	}
+	e.tick++
+	item := &lfuItem[K]{key: key, freq: 1, tick: e.tick}
+	e.m[key] = item
+	heap.Push(&e.h, item)
+}
+
+func (e *lfu[K]) touch(key K) {
+	item, ok := e.m[key]
+	if !ok {
+		return
+	}
+	e.tick++
+	item.freq++
+	item.tick = e.tick
+	heap.Fix(&e.h, item.index)
+}
+
+func (e *lfu[K]) remove(key K) {
+	item, ok := e.m[key]
+	if !ok {
+		return
+	}
+	heap.Remove(&e.h, item.index)
+	delete(e.m, key)
+}
+
+func (e *lfu[K]) victim(skip K) (K, bool) {
+	if len(e.h) == 0 {
+		return *new(K), false
+	}
+	if e.h[0].key != skip {
+		return e.h[0].key, true
+	}
+	// the next smallest item is one of the children of the root
+	best := -1
+	for i := 1; i <= 2 && i < len(e.h); i++ {
+		if best == -1 || e.h.Less(i, best) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return *new(K), false
+	}
+	return e.h[best].key, true
+}
@@ -0,0 +1,223 @@
+package wtype_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestKeyedCache_EvictLRU(t *testing.T) {
+	c := wtype.NewKeyedCache[string, int](0, wtype.WithCapacity(2))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // a is now the most recently used
+	c.Set("c", 3)
+
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should still exist")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("c should exist")
+	}
+
+	t.Run("updating an existing key does not evict", func(t *testing.T) {
+		c.Set("a", 10)
+		if c.Len() != 2 {
+			t.Errorf("expected 2 entries, got %d", c.Len())
+		}
+		if _, ok := c.Get("c"); !ok {
+			t.Error("c should still exist")
+		}
+	})
+}
+
+func TestKeyedCache_EvictLFU(t *testing.T) {
+	c := wtype.NewKeyedCache[string, int](0,
+		wtype.WithCapacity(2),
+		wtype.WithEvictionPolicy(wtype.EvictLFU),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 3) // b has the lowest frequency
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should still exist")
+	}
+
+	c.Set("d", 4) // c and d tie on frequency with c being older
+	if _, ok := c.Get("c"); ok {
+		t.Error("c should have been evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestKeyedCache_EvictAfterDelete(t *testing.T) {
+	c := wtype.NewKeyedCache[int, int](0, wtype.WithCapacity(3))
+	for i := 0; i < 3; i++ {
+		c.Set(i, i)
+	}
+	c.Delete(0)
+	c.Set(3, 3)
+	if c.Len() != 3 {
+		t.Errorf("expected 3 entries, got %d", c.Len())
+	}
+	for i := 1; i <= 3; i++ {
+		if _, ok := c.Get(i); !ok {
+			t.Errorf("%d should exist", i)
+		}
+	}
+
+	c.Clear()
+	for i := 0; i < 10; i++ {
+		c.Set(i, i)
+	}
+	if c.Len() != 3 {
+		t.Errorf("expected 3 entries after clear, got %d", c.Len())
+	}
+}
+
+func TestEvictionPolicy_String(t *testing.T) {
+	if wtype.EvictLRU.String() != "LRU" || wtype.EvictLFU.String() != "LFU" {
+		t.Error("unexpected policy names")
+	}
+}
+
+func TestKeyedCache_MaxSize(t *testing.T) {
+	c := wtype.NewKeyedCache[string, []byte](0)
+	c.SetMaxSize(10*wtype.KB, func(b []byte) wtype.FileSize { return wtype.FileSize(len(b)) })
+
+	c.Set("a", make([]byte, 4*wtype.KB))
+	c.Set("b", make([]byte, 4*wtype.KB))
+	if c.Size() != 8*wtype.KB {
+		t.Errorf("expected 8KB, got %v", c.Size())
+	}
+
+	c.Get("a")
+	c.Set("c", make([]byte, 4*wtype.KB)) // over budget, b is the least recently used
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if c.Size() != 8*wtype.KB || c.Size().String() != "8.0KB" {
+		t.Errorf("expected 8.0KB, got %v", c.Size())
+	}
+
+	t.Run("growing an entry evicts others", func(t *testing.T) {
+		c.Set("c", make([]byte, 8*wtype.KB))
+		if _, ok := c.Get("a"); ok {
+			t.Error("a should have been evicted")
+		}
+		if v, ok := c.Get("c"); !ok || len(v) != 8*int(wtype.KB) {
+			t.Error("c should hold the new value")
+		}
+		if c.Size() != 8*wtype.KB {
+			t.Errorf("expected 8KB, got %v", c.Size())
+		}
+	})
+
+	t.Run("an entry over the budget is not kept", func(t *testing.T) {
+		evictions := c.Stats().Evictions
+		c.Set("huge", make([]byte, 11*wtype.KB))
+		if _, ok := c.Get("huge"); ok {
+			t.Error("huge should not be kept")
+		}
+		if n := c.Stats().Evictions - evictions; n != 1 {
+			t.Errorf("expected one eviction, got %d", n)
+		}
+		if _, ok := c.Get("c"); !ok {
+			t.Error("c should survive an oversized set")
+		}
+		if c.Size() != 8*wtype.KB || c.Len() != 1 {
+			t.Errorf("expected 8KB in 1 entry, got %v in %d entries", c.Size(), c.Len())
+		}
+
+		c.Set("c", make([]byte, 11*wtype.KB))
+		if _, ok := c.Get("c"); ok {
+			t.Error("the old value of c should be removed")
+		}
+		if c.Size() != 0 || c.Len() != 0 {
+			t.Errorf("expected an empty cache, got %v in %d entries", c.Size(), c.Len())
+		}
+	})
+
+	t.Run("delete releases the size", func(t *testing.T) {
+		c.Set("d", make([]byte, wtype.KB))
+		c.Delete("d")
+		if c.Size() != 0 {
+			t.Errorf("expected 0B, got %v", c.Size())
+		}
+	})
+}
+
+func TestKeyedCache_MaxSizeLFU(t *testing.T) {
+	c := wtype.NewKeyedCache[string, string](0, wtype.WithEvictionPolicy(wtype.EvictLFU))
+	c.SetMaxSize(10, func(s string) wtype.FileSize { return wtype.FileSize(len(s)) })
+	c.Set("a", "aaaa")
+	c.Set("b", "bbbb")
+	c.Get("b")
+	c.Get("b")
+	c.Set("a", "aaaaaaa") // a is the least frequently used, but it must not evict itself
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should exist")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+}
+
+func TestKeyedCache_SetMaxSizeLater(t *testing.T) {
+	c := wtype.NewKeyedCache[string, string](0)
+	c.Set("a", "aaaa")
+	c.Set("b", "bbbb")
+	c.Set("c", "cccccccccccc")
+
+	c.SetMaxSize(10, func(s string) wtype.FileSize { return wtype.FileSize(len(s)) })
+	if _, ok := c.Get("c"); ok {
+		t.Error("c is over the budget on its own and should have been evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a should be kept")
+	}
+	if c.Size() != 8 || c.Len() != 2 {
+		t.Errorf("expected 8B in 2 entries, got %v in %d entries", c.Size(), c.Len())
+	}
+
+	c.SetMaxSize(5, func(s string) wtype.FileSize { return wtype.FileSize(len(s)) })
+	if _, ok := c.Get("b"); ok {
+		t.Error("b is the least recently used and should have been evicted")
+	}
+	if c.Size() != 4 || c.Len() != 1 {
+		t.Errorf("expected 4B in 1 entry, got %v in %d entries", c.Size(), c.Len())
+	}
+}
+
+func TestLoadingCache_SetMaxSize(t *testing.T) {
+	c := wtype.NewLoadingCache(0, func(_ context.Context, key int) (string, error) {
+		return strings.Repeat("x", key), nil
+	})
+	c.SetMaxSize(10, func(s string) wtype.FileSize { return wtype.FileSize(len(s)) })
+
+	for _, key := range []int{4, 4, 5, 20} {
+		if _, err := c.Get(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected keys 4 and 5 to be kept, got %d entries", c.Len())
+	}
+}
@@ -0,0 +1,155 @@
+package wtype
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// deadline returns the time d after now, or the zero time if d is 0 so that it never expires.
+//
+//	A negative d returns now, so the data expires at once.
+func deadline(now time.Time, d time.Duration) time.Time {
+	switch {
+	case d == 0:
+		return time.Time{}
+	case d < 0:
+		return now
+	}
+	return now.Add(d)
+}
+
+// capDeadline limits expireAt to maxLife after created.
+//
+//	A zero expireAt or a maxLife <= 0 is returned unchanged.
+func capDeadline(expireAt, created time.Time, maxLife time.Duration) time.Time {
+	if expireAt.IsZero() || maxLife <= 0 {
+		return expireAt
+	}
+	if limit := created.Add(maxLife); expireAt.After(limit) {
+		return limit
+	}
+	return expireAt
+}
+
+// jitter is the random spread applied to a duration each time data is given a deadline.
+type jitter struct {
+	fraction float64       // spread as a fraction of the duration
+	spread   time.Duration // fixed spread
+}
+
+// apply returns d moved by a random amount within the spread in either direction.
+//
+//	A d <= 0 is returned unchanged so it keeps its meaning, and the result for a positive d is always positive.
+func (j jitter) apply(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	spread := j.spread + time.Duration(float64(d)*j.fraction)
+	if spread <= 0 {
+		return d
+	}
+	d += time.Duration(rand.Int64N(2*int64(spread)+1)) - spread
+	return max(d, 1)
+}
+
+// expired reports whether the deadline expireAt has passed at now.
+func expired(expireAt, now time.Time) bool {
+	return !expireAt.IsZero() && !now.Before(expireAt)
+}
+
+// Expirer is implemented by caches that can drop their expired data.
+type Expirer interface {
+	DeleteExpired()
+}
+
+// Janitor periodically calls DeleteExpired on registered caches from a single goroutine.
+//
+//	Caches expire lazily on access, so a Janitor is only needed to release memory held by
+//	data that is no longer read, or to report expiry to listeners promptly.
+//	Only concurrency-safe caches such as SafeCache and KeyedCache should be registered.
+type Janitor struct {
+	items    map[Expirer]struct{}
+	interval time.Duration
+	clock    Clock
+	t        ClockTimer
+	stopped  bool
+	mutex    sync.Mutex
+}
+
+// Add registers e with the janitor.
+//
+//	The janitor keeps a reference to e until Remove is called.
+func (j *Janitor) Add(e Expirer) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.items[e] = struct{}{}
+}
+
+// Remove unregisters e from the janitor.
+func (j *Janitor) Remove(e Expirer) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	delete(j.items, e)
+}
+
+// Len returns the number of registered caches.
+func (j *Janitor) Len() int {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return len(j.items)
+}
+
+// Sweep calls DeleteExpired on every registered cache.
+func (j *Janitor) Sweep() {
+	j.mutex.Lock()
+	items := make([]Expirer, 0, len(j.items))
+	for e := range j.items {
+		items = append(items, e)
+	}
+	j.mutex.Unlock()
+
+	for _, e := range items {
+		e.DeleteExpired()
+	}
+}
+
+// Stop stops the background sweeps.
+//
+//	It is safe to call Stop more than once.
+func (j *Janitor) Stop() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.stopped = true
+	if j.t != nil {
+		j.t.Stop()
+	}
+}
+
+// schedule arms the next sweep unless the janitor has been stopped.
+func (j *Janitor) schedule() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.stopped || j.interval <= 0 {
+		return
+	}
+	j.t = j.clock.AfterFunc(j.interval, func() {
+		j.Sweep()
+		j.schedule()
+	})
+}
+
+// NewJanitor creates a janitor that sweeps its caches every interval.
+//
+//	If interval <= 0, no background sweep runs and Sweep must be called manually.
+//	WithClock sets the clock that schedules the sweeps. Call Stop when the janitor is no longer needed.
+func NewJanitor(interval time.Duration, opts ...ClockOption) *Janitor {
+	o := newCacheOptions(opts)
+	j := &Janitor{
+		items:    make(map[Expirer]struct{}),
+		interval: interval,
+		clock:    o.clock,
+	}
+	j.schedule()
+	return j
+}
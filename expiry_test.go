@@ -0,0 +1,95 @@
+package wtype_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestSafeCache_ExpiryRace(t *testing.T) {
+	// run with -race: expiry must not write the data outside the lock
+	c := wtype.NewSafeCache(time.Millisecond, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Set(i)
+				_ = c.Get()
+				c.DeleteExpired()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestJanitor(t *testing.T) {
+	var expiredCount atomic.Int32
+	clock := wtype.NewFakeClock(clockStart)
+	j := wtype.NewJanitor(10*time.Second, wtype.WithClock(clock))
+	defer j.Stop()
+
+	caches := make([]*wtype.SafeCache[int], 100)
+	for i := range caches {
+		caches[i] = wtype.NewSafeCacheWithOptions[int](20*time.Second, wtype.WithClock(clock))
+		caches[i].Set(i + 1)
+		caches[i].AddListener(func(e wtype.CacheEvent[int]) {
+			if e.Reason == wtype.CacheExpired {
+				expiredCount.Add(1)
+			}
+		})
+		j.Add(caches[i])
+	}
+	keyed := wtype.NewKeyedCache[int, int](20*time.Second, wtype.WithClock(clock))
+	keyed.Set(1, 1)
+	j.Add(keyed)
+
+	if j.Len() != 101 {
+		t.Errorf("expected 101 registered caches, got %d", j.Len())
+	}
+
+	clock.Advance(19 * time.Second)
+	if expiredCount.Load() != 0 {
+		t.Errorf("expected nothing to expire yet, got %d", expiredCount.Load())
+	}
+	clock.Advance(time.Second)
+	if expiredCount.Load() != 100 {
+		t.Errorf("expected the janitor to expire 100 caches, got %d", expiredCount.Load())
+	}
+	if keyed.Len() != 0 {
+		t.Errorf("expected keyed cache to be empty, got %d", keyed.Len())
+	}
+
+	j.Remove(keyed)
+	if j.Len() != 100 {
+		t.Errorf("expected 100 registered caches, got %d", j.Len())
+	}
+	j.Stop()
+	j.Stop() // must not panic
+}
+
+func TestCache_DeleteExpired(t *testing.T) {
+	var events int
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewCacheWithOptions[string](10*time.Second, wtype.WithClock(clock))
+	c.Set("data")
+	c.AddListener(func(e wtype.CacheEvent[string]) {
+		events++
+	})
+
+	c.DeleteExpired()
+	if events != 0 {
+		t.Error("data should not expire yet")
+	}
+
+	clock.Advance(10 * time.Second)
+	c.DeleteExpired()
+	c.DeleteExpired()
+	if events != 1 {
+		t.Errorf("expected one expired event, got %d", events)
+	}
+}
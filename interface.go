@@ -10,6 +10,9 @@ type ICache[T any] interface {
 	StopTimer()
 }
 
+// ISet is the common interface of the set types.
+//
+//	Add may panic for values an implementation cannot hold, such as BitSet values above MaxBitSetValue.
 type ISet[T comparable] interface {
 	Add(T)
 	Get() []T
@@ -0,0 +1,489 @@
+package wtype
+
+import (
+	"sync"
+	"time"
+)
+
+// keyedEntry is a single value stored in a KeyedCache.
+type keyedEntry[V any] struct {
+	data     V
+	d        time.Duration
+	expireAt time.Time // zero while the entry never expires
+	created  time.Time // when the entry was last set
+	size     FileSize
+	tags     []string
+}
+
+// KeyedCache is a thread-safe cache that holds many values, each with its own lifetime.
+//
+//	It follows the same semantics as Cache, applied per key. Expired entries are removed when they are
+//	accessed or when DeleteExpired is called, for example by a Janitor.
+type KeyedCache[K comparable, V any] struct {
+	m        map[K]*keyedEntry[V]
+	d        time.Duration
+	capacity int
+	maxSize  FileSize
+	size     FileSize
+	sizer    func(V) FileSize
+	policy   EvictionPolicy
+	evict    evictor[K] // nil when the cache is unbounded
+	tags     map[string]*Set[K]
+	clock    Clock
+	sliding  bool
+	maxLife  time.Duration
+	jitter   jitter
+	stats    cacheStats
+	mutex    sync.RWMutex
+}
+
+// setTimer sets the expiry of the entry from its duration.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) setTimer(e *keyedEntry[V], now time.Time) {
+	e.expireAt = deadline(now, c.jitter.apply(e.d))
+	if c.sliding {
+		e.expireAt = capDeadline(e.expireAt, e.created, c.maxLife)
+	}
+}
+
+// lookup returns the entry stored under key, removing it if it has expired.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) lookup(key K, now time.Time) (*keyedEntry[V], bool) {
+	e, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+	if expired(e.expireAt, now) {
+		c.remove(key)
+		c.stats.expirations.Add(1)
+		return nil, false
+	}
+	return e, true
+}
+
+// deleteExpired removes every expired entry.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) deleteExpired(now time.Time) {
+	for k, e := range c.m {
+		if expired(e.expireAt, now) {
+			c.remove(k)
+			c.stats.expirations.Add(1)
+		}
+	}
+}
+
+// DeleteExpired removes every expired entry.
+//
+//	It implements Expirer so the cache can be registered with a Janitor.
+func (c *KeyedCache[K, V]) DeleteExpired() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deleteExpired(c.clock.Now())
+}
+
+// remove deletes the entry stored under key.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) remove(key K) {
+	e, ok := c.m[key]
+	if !ok {
+		return
+	}
+	c.size -= e.size
+	c.untag(key, e)
+	delete(c.m, key)
+	if c.evict != nil {
+		c.evict.remove(key)
+	}
+}
+
+// makeRoom evicts entries other than key until key fits in the cache.
+//
+//	isNew reports whether key is about to be added, and grow is how much the total size will grow.
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) makeRoom(key K, isNew bool, grow FileSize) {
+	if c.evict == nil {
+		return
+	}
+	for (isNew && c.capacity > 0 && len(c.m) >= c.capacity) ||
+		(c.maxSize > 0 && c.size+grow > c.maxSize) {
+		victim, ok := c.evict.victim(key)
+		if !ok {
+			return
+		}
+		c.remove(victim)
+		c.stats.evictions.Add(1)
+	}
+}
+
+// sizeOf returns the size of data, or 0 without a sizer.
+func (c *KeyedCache[K, V]) sizeOf(data V) FileSize {
+	if c.sizer == nil {
+		return 0
+	}
+	return c.sizer(data)
+}
+
+// SetMaxSize limits the total size of the entries, as reported by sizer, to max.
+//
+//	When a Set would go over max, entries are evicted according to the eviction policy. A value larger than
+//	max on its own is not stored, and any previous value of its key is removed; other entries are kept.
+//	Entries already in the cache are measured with sizer and evicted until they fit.
+//	If max is 0, the total size is only reported by Size.
+func (c *KeyedCache[K, V]) SetMaxSize(max FileSize, sizer func(V) FileSize) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxSize = max
+	c.sizer = sizer
+	c.size = 0
+	for _, e := range c.m {
+		e.size = c.sizeOf(e.data)
+		c.size += e.size
+	}
+	if c.maxSize <= 0 {
+		return
+	}
+	if c.evict == nil {
+		c.evict = newEvictor[K](c.policy)
+		for k := range c.m {
+			c.evict.add(k)
+		}
+	}
+	for k, e := range c.m {
+		if e.size > c.maxSize {
+			c.remove(k)
+			c.stats.evictions.Add(1)
+		}
+	}
+	// makeRoom never evicts the key it is given, so an entry under the zero key is kept; it fits on its own
+	c.makeRoom(*new(K), false, 0)
+}
+
+// Size returns the total size of the entries as reported by the sizer.
+//
+//	It is always 0 unless a sizer was given to SetMaxSize.
+func (c *KeyedCache[K, V]) Size() FileSize {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deleteExpired(c.clock.Now())
+	return c.size
+}
+
+// SetDuration sets the default duration used by Set.
+//
+//	Entries that already exist keep their current duration.
+func (c *KeyedCache[K, V]) SetDuration(d time.Duration) {
+	c.mutex.Lock()
+	c.d = d
+	c.mutex.Unlock()
+}
+
+// Set sets the data of key using the default duration.
+//
+//	Tags attached to the key by SetWithTags are kept.
+func (c *KeyedCache[K, V]) Set(key K, data V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(key, data, c.d)
+}
+
+// SetWithDuration sets the data of key with its own duration.
+//
+//	If d is 0, the entry will never expire, and if d is negative it expires at once. Tags attached to the key by SetWithTags are kept.
+func (c *KeyedCache[K, V]) SetWithDuration(key K, data V, d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(key, data, d)
+}
+
+// set stores the entry and sets its expiry.
+//
+//	It returns nil if the entry is larger than the size budget and was evicted at once.
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) set(key K, data V, d time.Duration) *keyedEntry[V] {
+	now := c.clock.Now()
+	size := c.sizeOf(data)
+	if c.maxSize > 0 && size > c.maxSize {
+		// the entry alone is over the budget, so only the key itself is dropped
+		c.remove(key)
+		c.stats.sets.Add(1)
+		c.stats.evictions.Add(1)
+		return nil
+	}
+	e, ok := c.lookup(key, now)
+	grow := size
+	if ok {
+		grow -= e.size
+	}
+	c.makeRoom(key, !ok, grow)
+
+	if ok {
+		if c.evict != nil {
+			c.evict.touch(key)
+		}
+	} else {
+		e = &keyedEntry[V]{}
+		c.m[key] = e
+		if c.evict != nil {
+			c.evict.add(key)
+		}
+	}
+	c.size += size - e.size
+	e.size = size
+	e.data = data
+	e.d = d
+	e.created = now
+	c.setTimer(e, now)
+	c.stats.sets.Add(1)
+	return e
+}
+
+// SetWithTags sets the data of key using the default duration and attaches tags to it.
+//
+//	The tags replace any tags the key had before. Use InvalidateTag to remove every entry with a tag.
+func (c *KeyedCache[K, V]) SetWithTags(key K, data V, tags ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setTagged(key, data, c.d, tags)
+}
+
+// SetWithDurationAndTags sets the data of key with its own duration and attaches tags to it.
+//
+//	If d is 0, the entry will never expire, and if d is negative it expires at once. The tags replace any tags the key had before.
+func (c *KeyedCache[K, V]) SetWithDurationAndTags(key K, data V, d time.Duration, tags ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setTagged(key, data, d, tags)
+}
+
+// setTagged is set followed by replacing the tags of the entry.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) setTagged(key K, data V, d time.Duration, tags []string) *keyedEntry[V] {
+	e := c.set(key, data, d)
+	if e != nil {
+		c.untag(key, e)
+		c.tag(key, e, tags)
+	}
+	return e
+}
+
+// InvalidateTag removes every entry tagged with tag and returns how many were removed.
+func (c *KeyedCache[K, V]) InvalidateTag(tag string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keys, ok := c.tags[tag]
+	if !ok {
+		return 0
+	}
+	n := 0
+	for _, key := range keys.Values() {
+		c.remove(key)
+		n++
+	}
+	return n
+}
+
+// tag attaches tags to the entry.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) tag(key K, e *keyedEntry[V], tags []string) {
+	for _, t := range tags {
+		keys, ok := c.tags[t]
+		if !ok {
+			keys = NewSet[K]()
+			c.tags[t] = keys
+		}
+		if !keys.Contains(key) {
+			keys.Add(key)
+			e.tags = append(e.tags, t)
+		}
+	}
+}
+
+// untag detaches every tag from the entry.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) untag(key K, e *keyedEntry[V]) {
+	for _, t := range e.tags {
+		if keys, ok := c.tags[t]; ok {
+			keys.Remove(key)
+			if keys.Len() == 0 {
+				delete(c.tags, t)
+			}
+		}
+	}
+	e.tags = nil
+}
+
+// Get gets the data of key.
+//
+//	If the key does not exist or has expired, the zero value and false are returned.
+func (c *KeyedCache[K, V]) Get(key K) (V, bool) {
+	data, ok := c.get(key)
+	c.stats.lookup(ok)
+	return data, ok
+}
+
+// get is Get without recording a hit or a miss.
+func (c *KeyedCache[K, V]) get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.clock.Now()
+	e, ok := c.lookup(key, now)
+	if !ok {
+		return *new(V), false
+	}
+	if c.evict != nil {
+		c.evict.touch(key)
+	}
+	c.slide(e, now)
+	return e.data, true
+}
+
+// slide restarts the duration of the entry in sliding mode.
+//
+//	The caller must hold the lock.
+func (c *KeyedCache[K, V]) slide(e *keyedEntry[V], now time.Time) {
+	if c.sliding && !e.expireAt.IsZero() {
+		c.setTimer(e, now)
+	}
+}
+
+// TTL returns the time left before key expires.
+//
+//	It returns 0 and true if the key never expires, and false if the key does not exist.
+func (c *KeyedCache[K, V]) TTL(key K) (time.Duration, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.clock.Now()
+	e, ok := c.lookup(key, now)
+	if !ok {
+		return 0, false
+	}
+	if e.expireAt.IsZero() {
+		return 0, true
+	}
+	return e.expireAt.Sub(now), true
+}
+
+// Delete removes key from the cache.
+func (c *KeyedCache[K, V]) Delete(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.m[key]; ok {
+		c.remove(key)
+	}
+}
+
+// Stats returns a snapshot of the counters of the cache.
+func (c *KeyedCache[K, V]) Stats() CacheStats {
+	return c.stats.snapshot()
+}
+
+// Len returns the number of entries in the cache.
+//
+//	Expired entries are removed first, so it takes time proportional to the number of entries.
+func (c *KeyedCache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.deleteExpired(c.clock.Now())
+	return len(c.m)
+}
+
+// Range iterates over the cache and calls f for each entry.
+//
+//	If f returns false, the iteration stops.
+//	The entries are copied under a read lock, so f may safely use the cache.
+func (c *KeyedCache[K, V]) Range(f func(key K, value V) bool) {
+	c.mutex.RLock()
+	now := c.clock.Now()
+	keys := make([]K, 0, len(c.m))
+	values := make([]V, 0, len(c.m))
+	for k, e := range c.m {
+		if expired(e.expireAt, now) {
+			continue
+		}
+		keys = append(keys, k)
+		values = append(values, e.data)
+	}
+	c.mutex.RUnlock()
+
+	for i := range keys {
+		if !f(keys[i], values[i]) {
+			break
+		}
+	}
+}
+
+// Clear removes all entries from the cache.
+func (c *KeyedCache[K, V]) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.m = make(map[K]*keyedEntry[V])
+	c.tags = make(map[string]*Set[K])
+	c.size = 0
+	if c.evict != nil {
+		c.evict = newEvictor[K](c.policy)
+	}
+}
+
+// ResetTimer resets the timer of key.
+//
+//	It returns false if the key does not exist.
+func (c *KeyedCache[K, V]) ResetTimer(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.clock.Now()
+	e, ok := c.lookup(key, now)
+	if !ok {
+		return false
+	}
+	c.setTimer(e, now)
+	return true
+}
+
+// StopTimer stops the timer of key.
+//
+//	The data will be retained until it is deleted or set again.
+//	It returns false if the key does not exist.
+func (c *KeyedCache[K, V]) StopTimer(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.lookup(key, c.clock.Now())
+	if !ok {
+		return false
+	}
+	e.expireAt = time.Time{}
+	return true
+}
+
+// NewKeyedCache creates a new keyed cache.
+//
+//	d is the default duration of each entry. If d is 0, entries never expire.
+//	Use WithCapacity to bound the number of entries, or SetMaxSize to bound their total size.
+func NewKeyedCache[K comparable, V any](d time.Duration, opts ...KeyedCacheOption) *KeyedCache[K, V] {
+	return newKeyedCache[K, V](d, newCacheOptions(opts))
+}
+
+// newKeyedCache creates a new keyed cache from collected options, for the caches built on one.
+func newKeyedCache[K comparable, V any](d time.Duration, o cacheOptions) *KeyedCache[K, V] {
+	c := &KeyedCache[K, V]{
+		m:        make(map[K]*keyedEntry[V]),
+		tags:     make(map[string]*Set[K]),
+		d:        d,
+		capacity: o.capacity,
+		policy:   o.policy,
+		clock:    o.clock,
+		sliding:  o.sliding,
+		maxLife:  o.maxLife,
+		jitter:   o.jitter,
+	}
+	if c.capacity > 0 {
+		c.evict = newEvictor[K](c.policy)
+	}
+	return c
+}
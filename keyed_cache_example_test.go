@@ -0,0 +1,66 @@
+package wtype_test
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+// ExampleNewKeyedCache demonstrates a cache holding many values with their own lifetimes
+func ExampleNewKeyedCache() {
+	cache := wtype.NewKeyedCache[string, int](5 * time.Second)
+
+	cache.Set("apple", 1)
+	cache.Set("banana", 2)
+	cache.SetWithDuration("cherry", 3, 0) // never expires
+
+	v, ok := cache.Get("apple")
+	fmt.Println(v, ok)
+
+	_, ok = cache.Get("durian")
+	fmt.Println(ok)
+
+	fmt.Println(cache.Len())
+
+	// Output:
+	// 1 true
+	// false
+	// 3
+}
+
+// ExampleKeyedCache_Range demonstrates iterating over a keyed cache
+func ExampleKeyedCache_Range() {
+	cache := wtype.NewKeyedCache[string, int](0)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+
+	var keys []string
+	cache.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Strings(keys)
+	fmt.Println(keys)
+
+	// Output:
+	// [a b c]
+}
+
+// ExampleWithCapacity demonstrates a keyed cache bounded by LRU eviction
+func ExampleWithCapacity() {
+	cache := wtype.NewKeyedCache[string, int](0, wtype.WithCapacity(2))
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")    // a is used, so b becomes the least recently used
+	cache.Set("c", 3) // the cache is full, b is evicted
+
+	_, ok := cache.Get("b")
+	fmt.Println(ok, cache.Len())
+
+	// Output:
+	// false 2
+}
@@ -0,0 +1,239 @@
+package wtype_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestKeyedCache_SetGet(t *testing.T) {
+	c := wtype.NewKeyedCache[string, int](time.Second)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected missing key")
+	}
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected (1, true), got (%d, %v)", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a to be deleted")
+	}
+
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("expected empty cache, got %d", c.Len())
+	}
+}
+
+func TestKeyedCache_Expiration(t *testing.T) {
+	t.Run("entries expire independently", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock))
+		c.Set("short", 1)
+		c.SetWithDuration("long", 2, 4*time.Minute)
+		c.SetWithDuration("forever", 3, 0)
+
+		clock.Advance(2 * time.Minute)
+		if _, ok := c.Get("short"); ok {
+			t.Error("short should have expired")
+		}
+		if _, ok := c.Get("long"); !ok {
+			t.Error("long should still exist")
+		}
+		if _, ok := c.Get("forever"); !ok {
+			t.Error("forever should never expire")
+		}
+	})
+
+	t.Run("reset timer extends expiration", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock))
+		c.Set("a", 1)
+
+		clock.Advance(40 * time.Second)
+		if !c.ResetTimer("a") {
+			t.Fatal("expected ResetTimer to find a")
+		}
+
+		clock.Advance(40 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Error("timer should have been reset")
+		}
+
+		clock.Advance(20 * time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Error("a should have expired after reset duration")
+		}
+	})
+
+	t.Run("stop timer retains entry", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock))
+		c.Set("a", 1)
+		if !c.StopTimer("a") {
+			t.Fatal("expected StopTimer to find a")
+		}
+		if c.StopTimer("missing") || c.ResetTimer("missing") {
+			t.Error("expected missing key to report false")
+		}
+
+		clock.Advance(2 * time.Minute)
+		if _, ok := c.Get("a"); !ok {
+			t.Error("a should not expire after stopping timer")
+		}
+	})
+
+	t.Run("set duration applies to later sets", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](0, wtype.WithClock(clock))
+		c.Set("a", 1)
+		c.SetDuration(time.Minute)
+		c.Set("b", 2)
+
+		clock.Advance(2 * time.Minute)
+		if _, ok := c.Get("a"); !ok {
+			t.Error("a should keep its original duration")
+		}
+		if _, ok := c.Get("b"); ok {
+			t.Error("b should have expired")
+		}
+	})
+}
+
+func TestKeyedCache_Range(t *testing.T) {
+	c := wtype.NewKeyedCache[int, int](0)
+	for i := 1; i <= 3; i++ {
+		c.Set(i, i*10)
+	}
+
+	sum := 0
+	c.Range(func(key int, value int) bool {
+		sum += value
+		c.Delete(key) // modifying the cache during Range must not deadlock
+		return true
+	})
+	if sum != 60 {
+		t.Errorf("expected 60, got %d", sum)
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected empty cache, got %d", c.Len())
+	}
+}
+
+func TestKeyedCache_Concurrent(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewKeyedCache[int, int](time.Minute, wtype.WithClock(clock))
+	wg := sync.WaitGroup{}
+	wg.Add(100)
+	for i := 0; i < 100; i++ {
+		go func(i int) {
+			defer wg.Done()
+			c.Set(i%10, i)
+			c.Get(i % 10)
+			c.ResetTimer(i % 10)
+			if i%7 == 0 {
+				c.Delete(i % 10)
+			}
+		}(i)
+	}
+	wg.Wait()
+	clock.Advance(time.Minute)
+	if c.Len() != 0 {
+		t.Errorf("expected all entries to expire, got %d", c.Len())
+	}
+}
+
+func TestKeyedCache_InvalidateTag(t *testing.T) {
+	t.Run("removes tagged entries", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](0)
+		c.SetWithTags("a", 1, "tenant:1")
+		c.SetWithTags("b", 2, "tenant:1", "user:2")
+		c.SetWithTags("c", 3, "tenant:2")
+		c.Set("d", 4)
+
+		if n := c.InvalidateTag("tenant:1"); n != 2 {
+			t.Errorf("expected 2 entries removed, got %d", n)
+		}
+		if _, ok := c.Get("a"); ok {
+			t.Error("a should be invalidated")
+		}
+		if _, ok := c.Get("b"); ok {
+			t.Error("b should be invalidated")
+		}
+		if c.Len() != 2 {
+			t.Errorf("expected 2 entries, got %d", c.Len())
+		}
+		if n := c.InvalidateTag("user:2"); n != 0 {
+			t.Errorf("expected user:2 to be dropped with b, got %d", n)
+		}
+	})
+
+	t.Run("set keeps tags", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](0)
+		c.SetWithTags("a", 1, "x")
+		c.Set("a", 2)
+		c.SetWithDuration("a", 3, time.Minute)
+		if n := c.InvalidateTag("x"); n != 1 {
+			t.Errorf("expected a to keep tag x, got %d entries removed", n)
+		}
+	})
+
+	t.Run("set with tags replaces tags", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](0)
+		c.SetWithTags("a", 1, "x")
+		c.SetWithTags("a", 2, "y")
+		if n := c.InvalidateTag("x"); n != 0 {
+			t.Errorf("expected no entries tagged x, got %d", n)
+		}
+		if v, ok := c.Get("a"); !ok || v != 2 {
+			t.Errorf("expected (2, true), got (%d, %v)", v, ok)
+		}
+	})
+
+	t.Run("duration and tags", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](time.Second, wtype.WithClock(clock))
+		c.SetWithDurationAndTags("a", 1, time.Hour, "x")
+		clock.Advance(time.Minute)
+		if _, ok := c.Get("a"); !ok {
+			t.Error("expected a to use its own duration")
+		}
+		if n := c.InvalidateTag("x"); n != 1 {
+			t.Errorf("expected 1 entry removed, got %d", n)
+		}
+	})
+
+	t.Run("expired entries leave the index", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewKeyedCache[string, int](time.Second, wtype.WithClock(clock))
+		c.SetWithTags("a", 1, "x")
+		clock.Advance(2 * time.Second)
+		c.DeleteExpired()
+		c.SetWithTags("b", 2, "y")
+		if n := c.InvalidateTag("x"); n != 0 {
+			t.Errorf("expected expired entry to leave the index, got %d", n)
+		}
+	})
+
+	t.Run("evicted entries leave the index", func(t *testing.T) {
+		c := wtype.NewKeyedCache[string, int](0, wtype.WithCapacity(1))
+		c.SetWithTags("a", 1, "x")
+		c.SetWithTags("b", 2, "y")
+		if n := c.InvalidateTag("x"); n != 0 {
+			t.Errorf("expected evicted entry to leave the index, got %d", n)
+		}
+		if n := c.InvalidateTag("y"); n != 1 {
+			t.Errorf("expected 1 entry removed, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package wtype
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Loader loads the value of key for a LoadingCache.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// loadResult is a cached outcome of a Loader call.
+type loadResult[V any] struct {
+	data V
+	err  error
+}
+
+// LoadingCache is a read-through keyed cache.
+//
+//	On a miss the loader is called, and concurrent misses for the same key share a single call through
+//	singleflight, as with DoShared. Errors are cached for the negative duration so a failing backend
+//	is not called on every Get.
+type LoadingCache[K comparable, V any] struct {
+	cache    *KeyedCache[K, loadResult[V]]
+	loader   Loader[K, V]
+	negative atomic.Int64
+
+	group       singleflight.Group // per cache, so loads never share a flight with DoShared or another cache
+	flights     map[K]string       // singleflight key of each key with a load in flight
+	flightSeq   uint64
+	flightMutex sync.Mutex
+}
+
+// call returns the channel of the load in flight for key, starting one if there is none.
+//
+//	singleflight keys are strings, so each key in flight is given a unique one instead of its formatted
+//	text: keys that merely print the same never share a load.
+func (c *LoadingCache[K, V]) call(ctx context.Context, key K) <-chan singleflight.Result {
+	c.flightMutex.Lock()
+	defer c.flightMutex.Unlock()
+	id, ok := c.flights[key]
+	if !ok {
+		c.flightSeq++
+		id = strconv.FormatUint(c.flightSeq, 10)
+		c.flights[key] = id
+	}
+	return c.group.DoChan(id, func() (any, error) {
+		defer func() {
+			c.flightMutex.Lock()
+			delete(c.flights, key)
+			c.flightMutex.Unlock()
+		}()
+		return c.load(ctx, key), nil
+	})
+}
+
+// SetDuration sets the duration of successfully loaded values.
+func (c *LoadingCache[K, V]) SetDuration(d time.Duration) {
+	c.cache.SetDuration(d)
+}
+
+// SetNegativeDuration sets the duration of cached loader errors.
+//
+//	If d <= 0, errors are not cached and every Get after a failure calls the loader again.
+func (c *LoadingCache[K, V]) SetNegativeDuration(d time.Duration) {
+	c.negative.Store(int64(d))
+}
+
+// Get gets the value of key, loading it on a miss.
+//
+//	If ctx is done before the value is available, ctx.Err() is returned.
+//	The load itself is not cancelled, so other callers waiting for the same key still receive its result.
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	r, ok := c.cache.get(key)
+	c.cache.stats.lookup(ok)
+	if ok {
+		return r.data, r.err
+	}
+	if err := ctx.Err(); err != nil {
+		return *new(V), err
+	}
+
+	select {
+	case res := <-c.call(context.WithoutCancel(ctx), key):
+		r := res.Val.(loadResult[V])
+		return r.data, r.err
+	case <-ctx.Done():
+		return *new(V), ctx.Err()
+	}
+}
+
+// load calls the loader and caches its result.
+func (c *LoadingCache[K, V]) load(ctx context.Context, key K) loadResult[V] {
+	// a previous flight may have filled the cache after our miss
+	if r, ok := c.cache.get(key); ok {
+		return r
+	}
+
+	start := time.Now()
+	data, err := c.loader(ctx, key)
+	c.cache.stats.load(time.Since(start), err)
+	r := loadResult[V]{data: data, err: err}
+	if err == nil {
+		c.cache.Set(key, r)
+	} else if d := time.Duration(c.negative.Load()); d > 0 {
+		c.cache.SetWithDuration(key, r, d)
+	}
+	return r
+}
+
+// Set sets the value of key without calling the loader.
+func (c *LoadingCache[K, V]) Set(key K, data V) {
+	c.cache.Set(key, loadResult[V]{data: data})
+}
+
+// Delete removes key, so the next Get calls the loader again.
+func (c *LoadingCache[K, V]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+// SetMaxSize limits the total size of the cached values, as reported by sizer, to max.
+//
+//	Cached errors are measured with the zero value. See KeyedCache.SetMaxSize.
+func (c *LoadingCache[K, V]) SetMaxSize(max FileSize, sizer func(V) FileSize) {
+	var size func(loadResult[V]) FileSize
+	if sizer != nil {
+		size = func(r loadResult[V]) FileSize {
+			return sizer(r.data)
+		}
+	}
+	c.cache.SetMaxSize(max, size)
+}
+
+// Stats returns a snapshot of the counters of the cache.
+//
+//	A cached error counts as a hit.
+func (c *LoadingCache[K, V]) Stats() CacheStats {
+	return c.cache.Stats()
+}
+
+// Len returns the number of cached values and errors.
+func (c *LoadingCache[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// DeleteExpired removes expired values and errors.
+//
+//	It implements Expirer so the cache can be registered with a Janitor.
+func (c *LoadingCache[K, V]) DeleteExpired() {
+	c.cache.DeleteExpired()
+}
+
+// Clear removes all cached values and errors.
+func (c *LoadingCache[K, V]) Clear() {
+	c.cache.Clear()
+}
+
+// NewLoadingCache creates a new loading cache.
+//
+//	d is the duration of loaded values. If d is 0, they never expire.
+//	opts configure the underlying KeyedCache, such as WithCapacity, and WithNegativeDuration sets the negative duration.
+func NewLoadingCache[K comparable, V any](d time.Duration, loader Loader[K, V], opts ...LoadingCacheOption) *LoadingCache[K, V] {
+	return newLoadingCache(d, loader, newCacheOptions(opts))
+}
+
+// newLoadingCache creates a new loading cache from collected options, for Memoize.
+func newLoadingCache[K comparable, V any](d time.Duration, loader Loader[K, V], o cacheOptions) *LoadingCache[K, V] {
+	c := &LoadingCache[K, V]{
+		cache:   newKeyedCache[K, loadResult[V]](d, o),
+		loader:  loader,
+		flights: make(map[K]string),
+	}
+	c.SetNegativeDuration(o.negative)
+	return c
+}
@@ -0,0 +1,174 @@
+package wtype_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestLoadingCache_Get(t *testing.T) {
+	var calls atomic.Int32
+	c := wtype.NewLoadingCache(time.Second, func(ctx context.Context, key int) (int, error) {
+		calls.Add(1)
+		return key * 2, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		v, err := c.Get(context.Background(), 21)
+		if err != nil || v != 42 {
+			t.Fatalf("expected (42, nil), got (%d, %v)", v, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected loader to be called once, got %d", calls.Load())
+	}
+
+	c.Delete(21)
+	if _, err := c.Get(context.Background(), 21); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected loader to be called again after Delete, got %d", calls.Load())
+	}
+
+	c.Set(1, 100)
+	if v, _ := c.Get(context.Background(), 1); v != 100 {
+		t.Errorf("expected 100, got %d", v)
+	}
+}
+
+func TestLoadingCache_SharedLoad(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := wtype.NewLoadingCache(time.Second, func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return key + "!", nil
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(20)
+	for i := 0; i < 20; i++ {
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(context.Background(), "a")
+			if err != nil || v != "a!" {
+				t.Errorf("expected (a!, nil), got (%s, %v)", v, err)
+			}
+		}()
+	}
+	// every caller has missed before the load is released; a caller that joins late finds the value cached
+	for c.Stats().Misses < 20 {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected one shared load, got %d", calls.Load())
+	}
+}
+
+func TestLoadingCache_NegativeDuration(t *testing.T) {
+	errBackend := errors.New("backend down")
+	var calls atomic.Int32
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewLoadingCache(time.Hour, func(ctx context.Context, key int) (int, error) {
+		calls.Add(1)
+		return 0, errBackend
+	}, wtype.WithClock(clock))
+
+	t.Run("errors are not cached by default", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := c.Get(context.Background(), 1); !errors.Is(err, errBackend) {
+				t.Fatalf("expected backend error, got %v", err)
+			}
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected 2 loads, got %d", calls.Load())
+		}
+	})
+
+	t.Run("errors are cached for the negative duration", func(t *testing.T) {
+		calls.Store(0)
+		c.SetNegativeDuration(time.Minute)
+		for i := 0; i < 3; i++ {
+			if _, err := c.Get(context.Background(), 2); !errors.Is(err, errBackend) {
+				t.Fatalf("expected backend error, got %v", err)
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected 1 load, got %d", calls.Load())
+		}
+
+		clock.Advance(time.Minute)
+		_, _ = c.Get(context.Background(), 2)
+		if calls.Load() != 2 {
+			t.Errorf("expected the error to expire, got %d loads", calls.Load())
+		}
+	})
+}
+
+func TestLoadingCache_ContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	c := wtype.NewLoadingCache(time.Second, func(ctx context.Context, key int) (int, error) {
+		<-release
+		return key, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, 7); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	close(release)
+	v, err := c.Get(context.Background(), 7)
+	if err != nil || v != 7 {
+		t.Errorf("expected (7, nil), got (%d, %v)", v, err)
+	}
+}
+
+func TestLoadingCache_KeysThatPrintAlike(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	c := wtype.NewLoadingCache(time.Minute, func(ctx context.Context, key any) (string, error) {
+		started <- struct{}{}
+		<-release
+		return fmt.Sprintf("%T", key), nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	for i, key := range []any{int(1), int64(1)} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(context.Background(), key)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}()
+	}
+	// both loads must be in flight at once, otherwise one key shared the load of the other
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected a separate load for each key")
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if results[0] != "int" || results[1] != "int64" {
+		t.Errorf("expected [int int64], got %v", results)
+	}
+}
@@ -0,0 +1,19 @@
+package wtype
+
+import "context"
+
+// Memoize returns a cached version of fn.
+//
+//	It is built on a LoadingCache: concurrent calls with the same key share a single call of fn through
+//	singleflight, as with DoShared, and its result is cached.
+//	Use WithDuration for the duration of results, WithCapacity to limit the number of cached keys
+//	and WithNegativeDuration to cache errors. Without WithDuration, results never expire.
+func Memoize[K comparable, V any](fn func(K) (V, error), opts ...MemoizeOption) func(K) (V, error) {
+	o := newCacheOptions(opts)
+	c := newLoadingCache(o.duration, func(_ context.Context, key K) (V, error) {
+		return fn(key)
+	}, o)
+	return func(key K) (V, error) {
+		return c.Get(context.Background(), key)
+	}
+}
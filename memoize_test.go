@@ -0,0 +1,120 @@
+package wtype_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestMemoize(t *testing.T) {
+	t.Run("caches results", func(t *testing.T) {
+		var calls atomic.Int32
+		f := wtype.Memoize(func(key int) (int, error) {
+			calls.Add(1)
+			return key * 2, nil
+		})
+		for i := 0; i < 3; i++ {
+			if v, err := f(21); err != nil || v != 42 {
+				t.Fatalf("expected (42, nil), got (%d, %v)", v, err)
+			}
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected fn to be called once, got %d", calls.Load())
+		}
+	})
+
+	t.Run("shares concurrent calls", func(t *testing.T) {
+		var calls atomic.Int32
+		f := wtype.Memoize(func(key string) (string, error) {
+			calls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			return key, nil
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = f("a")
+			}()
+		}
+		wg.Wait()
+		if calls.Load() != 1 {
+			t.Errorf("expected fn to be called once, got %d", calls.Load())
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		var calls atomic.Int32
+		f := wtype.Memoize(func(key int) (int, error) {
+			calls.Add(1)
+			return key, nil
+		}, wtype.WithDuration(time.Second), wtype.WithClock(clock))
+		_, _ = f(1)
+		clock.Advance(2 * time.Second)
+		_, _ = f(1)
+		if calls.Load() != 2 {
+			t.Errorf("expected fn to be called again after expiry, got %d", calls.Load())
+		}
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		var calls atomic.Int32
+		f := wtype.Memoize(func(key int) (int, error) {
+			calls.Add(1)
+			return key, nil
+		}, wtype.WithCapacity(1))
+		_, _ = f(1)
+		_, _ = f(2)
+		_, _ = f(1)
+		if calls.Load() != 3 {
+			t.Errorf("expected 1 to be evicted, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		errFail := errors.New("fail")
+		var calls atomic.Int32
+		fn := func(key int) (int, error) {
+			calls.Add(1)
+			return 0, errFail
+		}
+
+		f := wtype.Memoize(fn)
+		_, _ = f(1)
+		if _, err := f(1); !errors.Is(err, errFail) {
+			t.Errorf("expected errFail, got %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected errors not to be cached, got %d calls", calls.Load())
+		}
+
+		calls.Store(0)
+		f = wtype.Memoize(fn, wtype.WithNegativeDuration(time.Minute))
+		_, _ = f(1)
+		if _, err := f(1); !errors.Is(err, errFail) {
+			t.Errorf("expected cached errFail, got %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected the error to be cached, got %d calls", calls.Load())
+		}
+	})
+}
+
+func TestMemoize_KeysThatPrintAlike(t *testing.T) {
+	f := wtype.Memoize(func(key any) (string, error) {
+		return fmt.Sprintf("%T", key), nil
+	})
+	if v, _ := f(int(1)); v != "int" {
+		t.Errorf("expected int, got %s", v)
+	}
+	if v, _ := f(int64(1)); v != "int64" {
+		t.Errorf("expected int64, got %s", v)
+	}
+}
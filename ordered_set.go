@@ -0,0 +1,143 @@
+package wtype
+
+import (
+	"encoding/json"
+	"iter"
+	"slices"
+	"sort"
+)
+
+// OrderedSet is a generic, non-thread-safe set that keeps its elements in insertion order.
+//
+//	Adding an element that is already in the set keeps its position. Remove takes time proportional
+//	to the number of elements after the removed one.
+type OrderedSet[T comparable] struct {
+	index  map[T]int
+	values []T
+}
+
+// MarshalJSON implementation json.Marshal
+//
+//	The elements are written in insertion order.
+func (s OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	if s.values == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.values)
+}
+
+// UnmarshalJSON implementation json.Unmarshal
+func (s *OrderedSet[T]) UnmarshalJSON(bytes []byte) error {
+	var data []T
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return err
+	}
+	s.Clear()
+	for _, v := range data {
+		s.Add(v)
+	}
+	return nil
+}
+
+// Values is an alias for Get.
+func (s *OrderedSet[T]) Values() []T {
+	return s.Get()
+}
+
+// Add adds an element to the end of the set if it is not already in it.
+func (s *OrderedSet[T]) Add(data T) {
+	if _, ok := s.index[data]; ok {
+		return
+	}
+	s.index[data] = len(s.values)
+	s.values = append(s.values, data)
+}
+
+// Get returns all elements in the set as a slice, in insertion order.
+func (s *OrderedSet[T]) Get() []T {
+	return slices.Clone(s.values)
+}
+
+// Len returns the number of elements in the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.values)
+}
+
+// Remove removes an element from the set.
+func (s *OrderedSet[T]) Remove(data T) {
+	i, ok := s.index[data]
+	if !ok {
+		return
+	}
+	delete(s.index, data)
+	s.values = slices.Delete(s.values, i, i+1)
+	for j := i; j < len(s.values); j++ {
+		s.index[s.values[j]] = j
+	}
+}
+
+// Contains checks if an element exists in the set.
+func (s *OrderedSet[T]) Contains(data T) bool {
+	_, ok := s.index[data]
+	return ok
+}
+
+// Clear removes all elements from the set.
+func (s *OrderedSet[T]) Clear() {
+	s.index = make(map[T]int)
+	s.values = nil
+}
+
+// Index returns the position of an element in insertion order, or -1 if it is not in the set.
+func (s *OrderedSet[T]) Index(data T) int {
+	if i, ok := s.index[data]; ok {
+		return i
+	}
+	return -1
+}
+
+// At returns the element at position i in insertion order.
+//
+//	It returns false if i is out of range.
+func (s *OrderedSet[T]) At(i int) (T, bool) {
+	if i < 0 || i >= len(s.values) {
+		return *new(T), false
+	}
+	return s.values[i], true
+}
+
+// Range iterates over the set in insertion order and calls f for each element.
+//
+//	If f returns false, the iteration stops.
+func (s *OrderedSet[T]) Range(f func(T) bool) {
+	for _, v := range s.values {
+		if !f(v) {
+			break
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set in insertion order.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// SortValues sort the set values
+//
+//	The set itself keeps its insertion order.
+func (s *OrderedSet[T]) SortValues(cmp func(a T, b T) bool) []T {
+	result := s.Values()
+	sort.Slice(result, func(i, j int) bool {
+		return cmp(result[i], result[j])
+	})
+	return result
+}
+
+// NewOrderedSet creates a new OrderedSet with val in order.
+func NewOrderedSet[T comparable](val ...T) *OrderedSet[T] {
+	s := OrderedSet[T]{index: make(map[T]int, len(val))}
+	for _, t := range val {
+		s.Add(t)
+	}
+	return &s
+}
@@ -0,0 +1,106 @@
+package wtype_test
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/wuchieh/wtype"
+)
+
+var (
+	_ wtype.ISet[int] = (*wtype.OrderedSet[int])(nil)
+	_ wtype.ISet[int] = (*wtype.SafeOrderedSet[int])(nil)
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := wtype.NewOrderedSet("c", "a", "b", "a")
+	if got := s.Values(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("expected [c a b], got %v", got)
+	}
+
+	s.Add("d")
+	s.Remove("a")
+	if got := s.Values(); !slices.Equal(got, []string{"c", "b", "d"}) {
+		t.Errorf("expected [c b d], got %v", got)
+	}
+	if s.Index("d") != 2 || s.Index("a") != -1 {
+		t.Errorf("unexpected Index results %d, %d", s.Index("d"), s.Index("a"))
+	}
+	if v, ok := s.At(1); !ok || v != "b" {
+		t.Errorf("expected (b, true), got (%s, %v)", v, ok)
+	}
+	if _, ok := s.At(3); ok {
+		t.Error("expected At(3) to be out of range")
+	}
+	if got := slices.Collect(s.All()); !slices.Equal(got, []string{"c", "b", "d"}) {
+		t.Errorf("expected All in insertion order, got %v", got)
+	}
+
+	s.Clear()
+	if s.Len() != 0 || s.Contains("c") {
+		t.Error("expected an empty set")
+	}
+}
+
+func TestOrderedSet_JSON(t *testing.T) {
+	s := wtype.NewOrderedSet(3, 1, 2)
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[3,1,2]" {
+		t.Errorf("expected [3,1,2], got %s", b)
+	}
+
+	var got wtype.OrderedSet[int]
+	if err := json.Unmarshal([]byte("[5,4,5,6]"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Values(), []int{5, 4, 6}) {
+		t.Errorf("expected [5 4 6], got %v", got.Values())
+	}
+
+	b, err = json.Marshal(wtype.NewOrderedSet[int]())
+	if err != nil || string(b) != "[]" {
+		t.Errorf("expected [], got %s (%v)", b, err)
+	}
+
+	safe := wtype.NewSafeOrderedSet[string]()
+	if err := json.Unmarshal([]byte(`["x","y"]`), safe); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(safe); string(b) != `["x","y"]` {
+		t.Errorf(`expected ["x","y"], got %s`, b)
+	}
+}
+
+func TestSafeOrderedSet(t *testing.T) {
+	s := wtype.NewSafeOrderedSet[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Add(i)
+				s.Index(i)
+				s.At(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 100 {
+		t.Errorf("expected 100 elements, got %d", s.Len())
+	}
+	for v := range s.All() {
+		if s.Index(v) < 0 {
+			t.Fatalf("expected %d to have an index", v)
+		}
+	}
+	s.Remove(0)
+	if v, _ := s.At(0); s.Index(v) != 0 {
+		t.Errorf("expected indexes to shift after Remove, got %d", s.Index(v))
+	}
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type SafeCache[T any] struct {
-	cache Cache[T]
-	mutex sync.RWMutex
+	cache   Cache[T]
+	mutex   sync.RWMutex
+	refresh *refresher[T] // nil unless SetRefresh has been called
 }
 
 // StopTimer stops the timer of the cache.
@@ -15,18 +16,22 @@ type SafeCache[T any] struct {
 //	The data will be retained permanently.
 func (s *SafeCache[T]) StopTimer() {
 	s.mutex.Lock()
-	s.cache.StopTimer()
-	s.mutex.Unlock()
+	s.cache.stopTimer()
+	s.stopRefresh()
+	s.unlock()
 }
 
 // ResetTimer resets the timer of the cache.
 func (s *SafeCache[T]) ResetTimer() {
 	s.mutex.Lock()
-	s.cache.ResetTimer()
-	s.mutex.Unlock()
+	s.cache.resetTimer()
+	s.armRefresh()
+	s.unlock()
 }
 
 // SetDuration sets the duration of the cache.
+//
+//	If duration is 0, data set afterwards never expires. If it is negative, the data expires at once.
 func (s *SafeCache[T]) SetDuration(duration time.Duration) {
 	s.mutex.Lock()
 	s.cache.SetDuration(duration)
@@ -36,15 +41,32 @@ func (s *SafeCache[T]) SetDuration(duration time.Duration) {
 // Set sets the data of the cache.
 func (s *SafeCache[T]) Set(data T) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.cache.Set(data)
+	defer s.unlock()
+	s.cache.set(data)
+	s.armRefresh()
 }
 
 // Get gets the data of the cache.
 func (s *SafeCache[T]) Get() T {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	return s.cache.Get()
+	defer s.unlock()
+	return s.cache.lookup()
+}
+
+// Stats returns a snapshot of the counters of the cache.
+//
+//	Refresh calls are counted as loads.
+func (s *SafeCache[T]) Stats() CacheStats {
+	return s.cache.Stats()
+}
+
+// DeleteExpired resets the data if it has expired.
+//
+//	It implements Expirer so the cache can be registered with a Janitor.
+func (s *SafeCache[T]) DeleteExpired() {
+	s.mutex.Lock()
+	defer s.unlock()
+	s.cache.expireIfDue()
 }
 
 // Use uses the data of the cache.
@@ -52,8 +74,9 @@ func (s *SafeCache[T]) Get() T {
 //	The data will be updated after the function is called.
 func (s *SafeCache[T]) Use(f func(T) T) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.cache.Set(f(s.cache.Get()))
+	defer s.unlock()
+	s.cache.set(f(s.cache.get()))
+	s.armRefresh()
 }
 
 // Use2 uses the data of the cache.
@@ -61,20 +84,67 @@ func (s *SafeCache[T]) Use(f func(T) T) {
 //	If the data is not set, the function will be called and the result will be set.
 func (s *SafeCache[T]) Use2(f func(T) (T, error)) error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	nd, err := f(s.cache.Get())
+	defer s.unlock()
+	nd, err := f(s.cache.get())
 	if err != nil {
 		return err
 	}
-	s.cache.Set(nd)
+	s.cache.set(nd)
+	s.armRefresh()
 	return nil
 }
 
+// GetWithVersion gets the data of the cache and its version.
+//
+//	The version changes whenever the data is set, expires or is refreshed. Pass it to CompareAndSet
+//	to write a value computed from the data without holding the lock during the computation.
+func (s *SafeCache[T]) GetWithVersion() (T, uint64) {
+	s.mutex.Lock()
+	defer s.unlock()
+	return s.cache.lookup(), s.cache.version
+}
+
+// CompareAndSet sets the data only if its version is still version, and reports whether it did.
+func (s *SafeCache[T]) CompareAndSet(version uint64, data T) bool {
+	s.mutex.Lock()
+	defer s.unlock()
+	s.cache.expireIfDue()
+	if s.cache.version != version {
+		return false
+	}
+	s.cache.set(data)
+	s.armRefresh()
+	return true
+}
+
+// AddListener registers l to be called when the data expires, is replaced or its timer is stopped.
+func (s *SafeCache[T]) AddListener(l CacheListener[T]) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cache.AddListener(l)
+}
+
+// unlock releases the lock and then delivers the events recorded while it was held.
+func (s *SafeCache[T]) unlock() {
+	events, listeners := s.cache.events.take()
+	s.mutex.Unlock()
+	dispatchCacheEvents(events, listeners)
+}
+
 // NewSafeCache creates a new safe cache.
 func NewSafeCache[T any](d time.Duration, data ...T) *SafeCache[T] {
-	c := NewCache(d, data...)
-	s := &SafeCache[T]{
-		cache: *c,
+	s := NewSafeCacheWithOptions[T](d)
+	if len(data) > 0 {
+		s.cache.Set(data[0])
 	}
 	return s
 }
+
+// NewSafeCacheWithOptions creates a new empty safe cache configured by opts, such as WithClock or WithJitter.
+//
+//	Register listeners with AddListener.
+func NewSafeCacheWithOptions[T any](d time.Duration, opts ...CacheOption) *SafeCache[T] {
+	s := &SafeCache[T]{}
+	s.cache.init(d, newCacheOptions(opts))
+	return s
+}
@@ -0,0 +1,119 @@
+package wtype
+
+import (
+	"context"
+	"time"
+)
+
+// GetOrLoad gets the data of the cache, calling loader and setting its result if there is no data.
+//
+//	Concurrent callers wait for the lock, so loader is called once. If ctx is done while waiting for the lock
+//	or for loader, ctx.Err() is returned at once; loader keeps running with the lock held and its result is
+//	discarded, so the cache is left unchanged.
+func (s *SafeCache[T]) GetOrLoad(ctx context.Context, loader func(context.Context) (T, error)) (T, error) {
+	if err := s.lockContext(ctx); err != nil {
+		return *new(T), err
+	}
+	data := s.cache.lookup()
+	if s.cache.ok {
+		s.unlock()
+		return data, nil
+	}
+	return s.applyContext(ctx, func(ctx context.Context, _ T) (T, error) {
+		start := time.Now()
+		data, err := loader(ctx)
+		s.cache.stats.load(time.Since(start), err)
+		return data, err
+	})
+}
+
+// UseContext is Use2 with a context.
+//
+//	If ctx is done while waiting for the lock or for f, ctx.Err() is returned at once; f keeps running with
+//	the lock held and its result is discarded, so the cache is left unchanged.
+func (s *SafeCache[T]) UseContext(ctx context.Context, f func(context.Context, T) (T, error)) error {
+	if err := s.lockContext(ctx); err != nil {
+		return err
+	}
+	_, err := s.applyContext(ctx, f)
+	return err
+}
+
+// lockContext acquires the lock unless ctx is done first.
+func (s *SafeCache[T]) lockContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.mutex.TryLock() {
+		return nil
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		s.mutex.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-locked
+			s.mutex.Unlock()
+		}()
+		return ctx.Err()
+	}
+}
+
+// applyContext calls f with the data and sets its result, unless ctx is done first.
+//
+//	The caller must hold the lock, which is released once f returns. If ctx can never be done, f runs
+//	inline. Otherwise it runs in its own goroutine, and a panic in f is raised again in the caller,
+//	unless ctx was done first, in which case it is discarded with the result.
+func (s *SafeCache[T]) applyContext(ctx context.Context, f func(context.Context, T) (T, error)) (T, error) {
+	if ctx.Done() == nil {
+		defer s.unlock()
+		return s.applyResult(f(ctx, s.cache.get()))
+	}
+
+	var (
+		data     T
+		err      error
+		panicked any
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			panicked = recover()
+		}()
+		data, err = f(ctx, s.cache.get())
+	}()
+
+	select {
+	case <-done:
+		defer s.unlock()
+		if panicked != nil {
+			panic(panicked)
+		}
+		return s.applyResult(data, err)
+	case <-ctx.Done():
+		go func() {
+			<-done
+			s.unlock()
+		}()
+		return *new(T), ctx.Err()
+	}
+}
+
+// applyResult sets data unless f failed.
+//
+//	The caller must hold the lock.
+func (s *SafeCache[T]) applyResult(data T, err error) (T, error) {
+	if err != nil {
+		return *new(T), err
+	}
+	s.cache.set(data)
+	s.armRefresh()
+	return data, nil
+}
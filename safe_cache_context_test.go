@@ -0,0 +1,171 @@
+package wtype_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestSafeCache_GetOrLoad(t *testing.T) {
+	t.Run("loads once", func(t *testing.T) {
+		c := wtype.NewSafeCache[int](time.Minute)
+		var calls atomic.Int32
+		loader := func(context.Context) (int, error) {
+			calls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return 42, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if v, err := c.GetOrLoad(context.Background(), loader); err != nil || v != 42 {
+					t.Errorf("expected (42, nil), got (%d, %v)", v, err)
+				}
+			}()
+		}
+		wg.Wait()
+		if calls.Load() != 1 {
+			t.Errorf("expected loader to be called once, got %d", calls.Load())
+		}
+		if s := c.Stats(); s.Loads != 1 || s.Hits != 9 || s.Misses != 1 {
+			t.Errorf("unexpected stats %+v", s)
+		}
+	})
+
+	t.Run("loader error", func(t *testing.T) {
+		c := wtype.NewSafeCache[int](time.Minute)
+		errFail := errors.New("fail")
+		if _, err := c.GetOrLoad(context.Background(), func(context.Context) (int, error) {
+			return 1, errFail
+		}); !errors.Is(err, errFail) {
+			t.Errorf("expected errFail, got %v", err)
+		}
+		if c.Get() != 0 {
+			t.Errorf("expected no data after a failed load, got %d", c.Get())
+		}
+	})
+
+	t.Run("cancelled while loading", func(t *testing.T) {
+		c := wtype.NewSafeCache[int](time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		release := make(chan struct{})
+		_, err := c.GetOrLoad(ctx, func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected DeadlineExceeded, got %v", err)
+		}
+		close(release)
+		if c.Get() != 0 {
+			t.Errorf("expected the late result to be discarded, got %d", c.Get())
+		}
+	})
+}
+
+func TestSafeCache_UseContext(t *testing.T) {
+	c := wtype.NewSafeCache[int](time.Minute, 1)
+	if err := c.UseContext(context.Background(), func(_ context.Context, v int) (int, error) {
+		return v + 1, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Get() != 2 {
+		t.Errorf("expected 2, got %d", c.Get())
+	}
+
+	t.Run("cancelled while waiting for the lock", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go c.Use(func(v int) int {
+			close(started)
+			<-release
+			return v * 10
+		})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := c.UseContext(ctx, func(_ context.Context, v int) (int, error) {
+			return -1, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected DeadlineExceeded, got %v", err)
+		}
+		close(release)
+		if c.Get() != 20 {
+			t.Errorf("expected 20, got %d", c.Get())
+		}
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		called := false
+		if err := c.UseContext(ctx, func(_ context.Context, v int) (int, error) {
+			called = true
+			return v, nil
+		}); !errors.Is(err, context.Canceled) || called {
+			t.Errorf("expected Canceled without calling f, got %v (called %v)", err, called)
+		}
+	})
+}
+
+func TestSafeCache_UseContextPanic(t *testing.T) {
+	contexts := map[string]func() (context.Context, context.CancelFunc){
+		"background": func() (context.Context, context.CancelFunc) {
+			return context.Background(), func() {}
+		},
+		"cancellable": func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		},
+	}
+	for name, newCtx := range contexts {
+		t.Run(name, func(t *testing.T) {
+			c := wtype.NewSafeCache[int](time.Minute, 1)
+			empty := wtype.NewSafeCache[int](time.Minute)
+			ctx, cancel := newCtx()
+			defer cancel()
+
+			func() {
+				defer func() {
+					if r := recover(); r != "boom" {
+						t.Errorf("expected the panic to reach the caller, got %v", r)
+					}
+				}()
+				_ = c.UseContext(ctx, func(context.Context, int) (int, error) {
+					panic("boom")
+				})
+			}()
+
+			// the lock must have been released and the data left unchanged
+			if c.Get() != 1 {
+				t.Errorf("expected 1, got %d", c.Get())
+			}
+			func() {
+				defer func() {
+					if r := recover(); r != "boom" {
+						t.Errorf("expected the loader panic to reach the caller, got %v", r)
+					}
+				}()
+				_, _ = empty.GetOrLoad(ctx, func(context.Context) (int, error) {
+					panic("boom")
+				})
+			}()
+			if _, err := empty.GetOrLoad(ctx, func(context.Context) (int, error) {
+				return 2, nil
+			}); err != nil {
+				t.Errorf("expected the lock to be released after a loader panic, got %v", err)
+			}
+		})
+	}
+}
@@ -129,3 +129,24 @@ func ExampleSafeCache_Use2() {
 	// Error occurred: cannot process empty string
 	// Data unchanged: ""
 }
+
+// ExampleSafeCache_CompareAndSet demonstrates an optimistic update that fails when the data changed meanwhile
+func ExampleSafeCache_CompareAndSet() {
+	safeCache := wtype.NewSafeCache[int](time.Minute, 1)
+
+	current, version := safeCache.GetWithVersion()
+	next := current * 10 // slow computation outside the lock
+
+	safeCache.Set(2) // another writer
+
+	fmt.Println("first attempt:", safeCache.CompareAndSet(version, next))
+
+	current, version = safeCache.GetWithVersion()
+	fmt.Println("second attempt:", safeCache.CompareAndSet(version, current*10))
+	fmt.Println("data:", safeCache.Get())
+
+	// Output:
+	// first attempt: false
+	// second attempt: true
+	// data: 20
+}
@@ -0,0 +1,169 @@
+package wtype
+
+import "time"
+
+// minRefreshDelay is the shortest delay between two refreshes, so that a refresh never runs in a busy loop.
+const minRefreshDelay = time.Millisecond
+
+// refresher holds the refresh-ahead settings of a SafeCache.
+type refresher[T any] struct {
+	f        func(T) (T, error)
+	ahead    time.Duration
+	maxStale time.Duration
+	onError  func(error)
+	t        ClockTimer
+	gen      uint64 // incremented whenever the data is replaced or the timer is stopped
+}
+
+// SetRefresh enables refresh-ahead mode.
+//
+//	When less than ahead remains before the data expires, f is called in the background with the current data
+//	and its result replaces the data. Until then, and for up to maxStale after expiry if f is slow or fails,
+//	readers keep getting the stale data. A failed refresh is not retried; the error is passed to the
+//	function set by OnRefreshError. The mode takes effect from the next Set. Passing a nil f disables it.
+//	If ahead is not less than the cache duration, the refresh starts halfway through the duration instead.
+func (s *SafeCache[T]) SetRefresh(f func(T) (T, error), ahead, maxStale time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var onError func(error)
+	if s.refresh != nil {
+		onError = s.refresh.onError
+	}
+	s.stopRefresh()
+
+	if f == nil {
+		s.refresh = nil
+		return
+	}
+	s.refresh = &refresher[T]{
+		f:        f,
+		ahead:    ahead,
+		maxStale: maxStale,
+		onError:  onError,
+	}
+}
+
+// OnRefreshError sets the function called when a background refresh fails.
+//
+//	It must be called after SetRefresh.
+func (s *SafeCache[T]) OnRefreshError(f func(error)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.refresh != nil {
+		s.refresh.onError = f
+	}
+}
+
+// armRefresh schedules the next refresh and extends the lifetime of the data by the maximum staleness.
+//
+//	The caller must hold the lock.
+func (s *SafeCache[T]) armRefresh() {
+	r := s.refresh
+	if r == nil {
+		return
+	}
+	s.stopRefresh()
+
+	d := s.cache.d
+	if d <= 0 || !s.cache.ok {
+		return
+	}
+	s.cache.setTimerFor(d + r.maxStale)
+
+	gen := r.gen
+	r.t = clockOrReal(s.cache.clock).AfterFunc(r.delay(d), func() {
+		s.doRefresh(gen)
+	})
+}
+
+// armRefreshAt is armRefresh for data that is fresh until freshUntil instead of for the duration from now.
+//
+//	It is used by Restore. The caller must hold the lock.
+func (s *SafeCache[T]) armRefreshAt(freshUntil time.Time) {
+	r := s.refresh
+	if r == nil {
+		return
+	}
+	s.stopRefresh()
+
+	if freshUntil.IsZero() || !s.cache.ok {
+		return
+	}
+	s.cache.expireAt = freshUntil.Add(r.maxStale)
+
+	clock := clockOrReal(s.cache.clock)
+	gen := r.gen
+	r.t = clock.AfterFunc(max(freshUntil.Sub(clock.Now())-r.ahead, 0), func() {
+		s.doRefresh(gen)
+	})
+}
+
+// delay returns how long after a Set the refresh for data fresh for d starts.
+func (r *refresher[T]) delay(d time.Duration) time.Duration {
+	if r.ahead >= d {
+		return max(d/2, minRefreshDelay)
+	}
+	return max(d-r.ahead, minRefreshDelay)
+}
+
+// freshUntil returns when the data stops being fresh, excluding the staleness allowed by refresh-ahead mode.
+//
+//	The caller must hold the lock.
+func (s *SafeCache[T]) freshUntil() time.Time {
+	if s.refresh != nil && !s.cache.expireAt.IsZero() {
+		return s.cache.expireAt.Add(-s.refresh.maxStale)
+	}
+	return s.cache.expireAt
+}
+
+// stopRefresh cancels a scheduled refresh.
+//
+//	The caller must hold the lock.
+func (s *SafeCache[T]) stopRefresh() {
+	r := s.refresh
+	if r == nil {
+		return
+	}
+	r.gen++
+	if r.t != nil {
+		r.t.Stop()
+		r.t = nil
+	}
+}
+
+// doRefresh reloads the data outside the lock.
+//
+//	The result is dropped if the data was replaced while f was running.
+func (s *SafeCache[T]) doRefresh(gen uint64) {
+	s.mutex.Lock()
+	r := s.refresh
+	if r == nil || r.gen != gen {
+		s.mutex.Unlock()
+		return
+	}
+	f := r.f
+	old := s.cache.get()
+	s.unlock()
+
+	start := time.Now()
+	nd, err := f(old)
+	s.cache.stats.load(time.Since(start), err)
+
+	s.mutex.Lock()
+	if s.refresh != r || r.gen != gen {
+		s.mutex.Unlock()
+		return
+	}
+	if err != nil {
+		onError := r.onError
+		s.mutex.Unlock()
+		if onError != nil {
+			onError(err)
+		}
+		return
+	}
+	s.cache.set(nd)
+	s.armRefresh()
+	s.unlock()
+}
@@ -0,0 +1,139 @@
+package wtype_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+// advanceAsync advances clock in a new goroutine, for timers whose callbacks block,
+// and returns a channel closed once Advance returns.
+func advanceAsync(clock *wtype.FakeClock, d time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		clock.Advance(d)
+	}()
+	return done
+}
+
+func TestSafeCache_Refresh(t *testing.T) {
+	t.Run("refreshes before expiry", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.SetRefresh(func(old int) (int, error) {
+			return old + 1, nil
+		}, 30*time.Second, 0)
+		c.Set(1)
+
+		clock.Advance(29 * time.Second)
+		if c.Get() != 1 {
+			t.Fatalf("expected 1, got %d", c.Get())
+		}
+		clock.Advance(time.Second)
+		if c.Get() != 2 {
+			t.Errorf("expected refreshed value 2, got %d", c.Get())
+		}
+		c.StopTimer()
+	})
+
+	t.Run("serves stale data while refreshing", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		started, release := make(chan struct{}), make(chan struct{})
+		var once sync.Once
+		c := wtype.NewSafeCacheWithOptions[string](30*time.Second, wtype.WithClock(clock))
+		c.SetRefresh(func(old string) (string, error) {
+			once.Do(func() { close(started) })
+			<-release
+			return "new", nil
+		}, 10*time.Second, time.Hour)
+		c.Set("old")
+
+		advanced := advanceAsync(clock, 20*time.Second) // the refresh starts and blocks
+		<-started
+		clock.Advance(20 * time.Second) // past the original expiry
+		if c.Get() != "old" {
+			t.Errorf("expected stale value, got %q", c.Get())
+		}
+
+		close(release)
+		<-advanced
+		if c.Get() != "new" {
+			t.Errorf("expected refreshed value, got %q", c.Get())
+		}
+		c.StopTimer()
+	})
+
+	t.Run("reports errors and expires after max staleness", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		errFailed := errors.New("failed")
+		var reported atomic.Int32
+		c := wtype.NewSafeCacheWithOptions[int](30*time.Second, wtype.WithClock(clock))
+		c.SetRefresh(func(old int) (int, error) {
+			return 0, errFailed
+		}, 10*time.Second, 30*time.Second)
+		c.OnRefreshError(func(err error) {
+			if errors.Is(err, errFailed) {
+				reported.Add(1)
+			}
+		})
+		c.Set(5)
+
+		clock.Advance(45 * time.Second)
+		if reported.Load() != 1 {
+			t.Errorf("expected one reported error, got %d", reported.Load())
+		}
+		if c.Get() != 5 {
+			t.Errorf("expected stale value 5, got %d", c.Get())
+		}
+
+		clock.Advance(15 * time.Second)
+		if c.Get() != 0 {
+			t.Errorf("expected data to expire after max staleness, got %d", c.Get())
+		}
+	})
+
+	t.Run("set during refresh wins", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		started, release := make(chan struct{}), make(chan struct{})
+		var once sync.Once
+		c := wtype.NewSafeCacheWithOptions[int](20*time.Second, wtype.WithClock(clock))
+		c.SetRefresh(func(old int) (int, error) {
+			once.Do(func() { close(started) })
+			<-release
+			return -1, nil
+		}, 10*time.Second, time.Hour)
+		c.Set(1)
+
+		advanced := advanceAsync(clock, 10*time.Second)
+		<-started
+		c.Set(2)
+		close(release)
+		<-advanced
+		if c.Get() != 2 {
+			t.Errorf("expected 2, got %d", c.Get())
+		}
+		c.SetRefresh(nil, 0, 0)
+	})
+
+	t.Run("ahead not less than duration", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		var calls int
+		c := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+		c.SetRefresh(func(old int) (int, error) {
+			calls++
+			return old + 1, nil
+		}, time.Minute, 0)
+		c.Set(0)
+
+		clock.Advance(time.Minute)
+		if calls != 2 || c.Get() != 2 {
+			t.Errorf("expected a refresh every 30s, got %d calls and value %d", calls, c.Get())
+		}
+		c.StopTimer()
+	})
+}
@@ -27,3 +27,48 @@ func TestSafeCache(t *testing.T) {
 		t.Errorf("Get %d, want 1000", s.Get())
 	}
 }
+
+func TestSafeCache_CompareAndSet(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	s := wtype.NewSafeCacheWithOptions[int](time.Second, wtype.WithClock(clock))
+
+	_, v0 := s.GetWithVersion()
+	if !s.CompareAndSet(v0, 1) {
+		t.Fatal("expected CompareAndSet on the initial version to succeed")
+	}
+	if s.CompareAndSet(v0, 2) {
+		t.Error("expected CompareAndSet with a stale version to fail")
+	}
+
+	data, v1 := s.GetWithVersion()
+	if data != 1 || v1 == v0 {
+		t.Errorf("expected data 1 with a new version, got (%d, %d)", data, v1)
+	}
+	clock.Advance(2 * time.Second)
+	if s.CompareAndSet(v1, 3) {
+		t.Error("expected expiry to change the version")
+	}
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		s := wtype.NewSafeCache(0, 0)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					for {
+						data, v := s.GetWithVersion()
+						if s.CompareAndSet(v, data+1) {
+							break
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if s.Get() != 800 {
+			t.Errorf("expected 800, got %d", s.Get())
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package wtype
+
+import (
+	"iter"
+	"sync"
+)
+
+// SafeOrderedSet is a thread-safe version of OrderedSet.
+type SafeOrderedSet[T comparable] struct {
+	mx sync.RWMutex
+	s  OrderedSet[T]
+}
+
+// MarshalJSON implementation json.Marshal
+//
+//	The elements are written in insertion order.
+func (s *SafeOrderedSet[T]) MarshalJSON() ([]byte, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.MarshalJSON()
+}
+
+// UnmarshalJSON implementation json.Unmarshal
+func (s *SafeOrderedSet[T]) UnmarshalJSON(bytes []byte) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.s.UnmarshalJSON(bytes)
+}
+
+// Values returns all elements in the set as a slice, in insertion order.
+func (s *SafeOrderedSet[T]) Values() []T {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.Values()
+}
+
+// Add adds an element to the end of the set if it is not already in it.
+func (s *SafeOrderedSet[T]) Add(data T) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.s.Add(data)
+}
+
+// Get returns all elements in the set as a slice, in insertion order.
+func (s *SafeOrderedSet[T]) Get() []T {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.Get()
+}
+
+// Len returns the number of elements in the set.
+func (s *SafeOrderedSet[T]) Len() int {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.Len()
+}
+
+// Remove removes an element from the set.
+func (s *SafeOrderedSet[T]) Remove(data T) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.s.Remove(data)
+}
+
+// Contains checks if an element exists in the set.
+func (s *SafeOrderedSet[T]) Contains(data T) bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.Contains(data)
+}
+
+// Clear removes all elements from the set.
+func (s *SafeOrderedSet[T]) Clear() {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.s.Clear()
+}
+
+// Index returns the position of an element in insertion order, or -1 if it is not in the set.
+func (s *SafeOrderedSet[T]) Index(data T) int {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.Index(data)
+}
+
+// At returns the element at position i in insertion order.
+//
+//	It returns false if i is out of range.
+func (s *SafeOrderedSet[T]) At(i int) (T, bool) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.At(i)
+}
+
+// Range iterates over the set in insertion order and calls f for each element.
+//
+//	If f returns false, the iteration stops.
+//	The iteration is performed on a copy taken under a read lock.
+func (s *SafeOrderedSet[T]) Range(f func(T) bool) {
+	for _, v := range s.Values() {
+		if !f(v) {
+			break
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set in insertion order.
+//
+//	Like Range, it iterates over a copy taken under a read lock.
+func (s *SafeOrderedSet[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// SortValues sort the set values
+func (s *SafeOrderedSet[T]) SortValues(cmp func(a T, b T) bool) []T {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.s.SortValues(cmp)
+}
+
+// NewSafeOrderedSet creates a new SafeOrderedSet with val in order.
+func NewSafeOrderedSet[T comparable](val ...T) *SafeOrderedSet[T] {
+	return &SafeOrderedSet[T]{
+		s: *NewOrderedSet(val...),
+	}
+}
@@ -1,6 +1,9 @@
 package wtype
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
 
 // SafeSet is a thread-safe version of Set.
 type SafeSet[T comparable] struct {
@@ -86,6 +89,13 @@ func (s *SafeSet[T]) Range(f func(T) bool) {
 	}
 }
 
+// All returns an iterator over the elements of the set.
+//
+//	Like Range, it iterates over a copy taken under a read lock, so the loop body may safely use the set.
+func (s *SafeSet[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
 // SortValues sort the set values
 func (s *SafeSet[T]) SortValues(cmp func(a T, b T) bool) []T {
 	s.mx.RLock()
@@ -100,3 +110,8 @@ func NewSafeSet[T comparable](val ...T) *SafeSet[T] {
 	}
 	return &s
 }
+
+// SafeSetFrom creates a new SafeSet with the elements of seq.
+func SafeSetFrom[T comparable](seq iter.Seq[T]) *SafeSet[T] {
+	return &SafeSet[T]{s: *SetFrom(seq)}
+}
@@ -2,6 +2,7 @@ package wtype_test
 
 import (
 	"encoding/json"
+	"slices"
 	"sync"
 	"testing"
 
@@ -141,3 +142,14 @@ func TestSafeSet_JSON(t *testing.T) {
 		return false
 	})
 }
+
+func TestSafeSet_All(t *testing.T) {
+	s := wtype.SafeSetFrom(slices.Values([]string{"a", "b", "a"}))
+	for v := range s.All() {
+		s.Add(v + v) // the loop body may use the set
+	}
+	got := slices.Sorted(s.All())
+	if !slices.Equal(got, []string{"a", "aa", "b", "bb"}) {
+		t.Errorf("expected [a aa b bb], got %v", got)
+	}
+}
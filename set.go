@@ -2,6 +2,7 @@ package wtype
 
 import (
 	"encoding/json"
+	"iter"
 	"sort"
 )
 
@@ -81,6 +82,19 @@ func (s *Set[T]) Range(f func(T) bool) {
 	}
 }
 
+// All returns an iterator over the elements of the set.
+//
+//	The order of elements is not guaranteed.
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range s.m {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
 // SortValues sort the set values
 func (s *Set[T]) SortValues(cmp func(a T, b T) bool) []T {
 	result := s.Values()
@@ -98,3 +112,12 @@ func NewSet[T comparable](val ...T) *Set[T] {
 	}
 	return &s
 }
+
+// SetFrom creates a new Set with the elements of seq.
+func SetFrom[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := NewSet[T]()
+	for v := range seq {
+		s.Add(v)
+	}
+	return s
+}
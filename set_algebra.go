@@ -0,0 +1,201 @@
+package wtype
+
+// smaller returns the operand with fewer elements first.
+func smaller[T comparable](a, b ISet[T]) (ISet[T], ISet[T]) {
+	if a.Len() <= b.Len() {
+		return a, b
+	}
+	return b, a
+}
+
+// SetUnion returns a new set with the elements that are in a or b.
+func SetUnion[T comparable](a, b ISet[T]) *Set[T] {
+	ret := &Set[T]{m: make(map[T]struct{}, max(a.Len(), b.Len()))}
+	a.Range(func(v T) bool {
+		ret.Add(v)
+		return true
+	})
+	b.Range(func(v T) bool {
+		ret.Add(v)
+		return true
+	})
+	return ret
+}
+
+// SetIntersection returns a new set with the elements that are in both a and b.
+func SetIntersection[T comparable](a, b ISet[T]) *Set[T] {
+	small, large := smaller(a, b)
+	ret := NewSet[T]()
+	small.Range(func(v T) bool {
+		if large.Contains(v) {
+			ret.Add(v)
+		}
+		return true
+	})
+	return ret
+}
+
+// SetDifference returns a new set with the elements of a that are not in b.
+func SetDifference[T comparable](a, b ISet[T]) *Set[T] {
+	ret := NewSet[T]()
+	a.Range(func(v T) bool {
+		if !b.Contains(v) {
+			ret.Add(v)
+		}
+		return true
+	})
+	return ret
+}
+
+// SetSymmetricDifference returns a new set with the elements that are in exactly one of a and b.
+func SetSymmetricDifference[T comparable](a, b ISet[T]) *Set[T] {
+	ret := SetDifference(a, b)
+	b.Range(func(v T) bool {
+		if !a.Contains(v) {
+			ret.Add(v)
+		}
+		return true
+	})
+	return ret
+}
+
+// SetIsSubset reports whether every element of a is in b.
+func SetIsSubset[T comparable](a, b ISet[T]) bool {
+	if a.Len() > b.Len() {
+		return false
+	}
+	ok := true
+	a.Range(func(v T) bool {
+		ok = b.Contains(v)
+		return ok
+	})
+	return ok
+}
+
+// SetIsSuperset reports whether every element of b is in a.
+func SetIsSuperset[T comparable](a, b ISet[T]) bool {
+	return SetIsSubset(b, a)
+}
+
+// SetIsDisjoint reports whether a and b have no element in common.
+func SetIsDisjoint[T comparable](a, b ISet[T]) bool {
+	small, large := smaller(a, b)
+	ok := true
+	small.Range(func(v T) bool {
+		ok = !large.Contains(v)
+		return ok
+	})
+	return ok
+}
+
+// SetEqual reports whether a and b have the same elements.
+func SetEqual[T comparable](a, b ISet[T]) bool {
+	return a.Len() == b.Len() && SetIsSubset(a, b)
+}
+
+// Union returns a new set with the elements that are in s or other.
+func (s *Set[T]) Union(other ISet[T]) *Set[T] {
+	return SetUnion[T](s, other)
+}
+
+// Intersection returns a new set with the elements that are in both s and other.
+func (s *Set[T]) Intersection(other ISet[T]) *Set[T] {
+	return SetIntersection[T](s, other)
+}
+
+// Difference returns a new set with the elements of s that are not in other.
+func (s *Set[T]) Difference(other ISet[T]) *Set[T] {
+	return SetDifference[T](s, other)
+}
+
+// SymmetricDifference returns a new set with the elements that are in exactly one of s and other.
+func (s *Set[T]) SymmetricDifference(other ISet[T]) *Set[T] {
+	return SetSymmetricDifference[T](s, other)
+}
+
+// IsSubsetOf reports whether every element of s is in other.
+func (s *Set[T]) IsSubsetOf(other ISet[T]) bool {
+	return SetIsSubset[T](s, other)
+}
+
+// IsSupersetOf reports whether every element of other is in s.
+func (s *Set[T]) IsSupersetOf(other ISet[T]) bool {
+	return SetIsSuperset[T](s, other)
+}
+
+// IsDisjoint reports whether s and other have no element in common.
+func (s *Set[T]) IsDisjoint(other ISet[T]) bool {
+	return SetIsDisjoint[T](s, other)
+}
+
+// Equal reports whether s and other have the same elements.
+func (s *Set[T]) Equal(other ISet[T]) bool {
+	return SetEqual[T](s, other)
+}
+
+// snapshot returns a copy of the set taken under the read lock.
+func (s *SafeSet[T]) snapshot() *Set[T] {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	ret := &Set[T]{m: make(map[T]struct{}, len(s.s.m))}
+	for k := range s.s.m {
+		ret.m[k] = struct{}{}
+	}
+	return ret
+}
+
+// operands returns consistent snapshots of s and other.
+//
+//	If other is a SafeSet, it is copied under its own lock, so the two locks are never held together.
+func (s *SafeSet[T]) operands(other ISet[T]) (*Set[T], ISet[T]) {
+	a := s.snapshot()
+	switch o := other.(type) {
+	case *SafeSet[T]:
+		if o == s {
+			return a, a
+		}
+		return a, o.snapshot()
+	default:
+		return a, other
+	}
+}
+
+// Union returns a new set with the elements that are in s or other.
+func (s *SafeSet[T]) Union(other ISet[T]) *SafeSet[T] {
+	return &SafeSet[T]{s: *SetUnion(s.operands(other))}
+}
+
+// Intersection returns a new set with the elements that are in both s and other.
+func (s *SafeSet[T]) Intersection(other ISet[T]) *SafeSet[T] {
+	return &SafeSet[T]{s: *SetIntersection(s.operands(other))}
+}
+
+// Difference returns a new set with the elements of s that are not in other.
+func (s *SafeSet[T]) Difference(other ISet[T]) *SafeSet[T] {
+	return &SafeSet[T]{s: *SetDifference(s.operands(other))}
+}
+
+// SymmetricDifference returns a new set with the elements that are in exactly one of s and other.
+func (s *SafeSet[T]) SymmetricDifference(other ISet[T]) *SafeSet[T] {
+	return &SafeSet[T]{s: *SetSymmetricDifference(s.operands(other))}
+}
+
+// IsSubsetOf reports whether every element of s is in other.
+func (s *SafeSet[T]) IsSubsetOf(other ISet[T]) bool {
+	return SetIsSubset(s.operands(other))
+}
+
+// IsSupersetOf reports whether every element of other is in s.
+func (s *SafeSet[T]) IsSupersetOf(other ISet[T]) bool {
+	return SetIsSuperset(s.operands(other))
+}
+
+// IsDisjoint reports whether s and other have no element in common.
+func (s *SafeSet[T]) IsDisjoint(other ISet[T]) bool {
+	return SetIsDisjoint(s.operands(other))
+}
+
+// Equal reports whether s and other have the same elements.
+func (s *SafeSet[T]) Equal(other ISet[T]) bool {
+	return SetEqual(s.operands(other))
+}
@@ -0,0 +1,95 @@
+package wtype_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/wuchieh/wtype"
+)
+
+// sorted returns the values of s in ascending order.
+func sorted(s wtype.ISet[int]) []int {
+	v := s.Values()
+	slices.Sort(v)
+	return v
+}
+
+func TestSet_Algebra(t *testing.T) {
+	a := wtype.NewSet(1, 2, 3, 4)
+	b := wtype.NewSafeSet(3, 4, 5)
+
+	tests := []struct {
+		name string
+		got  wtype.ISet[int]
+		want []int
+	}{
+		{"Union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"Intersection", a.Intersection(b), []int{3, 4}},
+		{"Difference", a.Difference(b), []int{1, 2}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 2, 5}},
+		{"SafeSet.Union", b.Union(a), []int{1, 2, 3, 4, 5}},
+		{"SafeSet.Intersection", b.Intersection(a), []int{3, 4}},
+		{"SafeSet.Difference", b.Difference(a), []int{5}},
+		{"SafeSet.SymmetricDifference", b.SymmetricDifference(a), []int{1, 2, 5}},
+		{"SetDifference", wtype.SetDifference[int](b, a), []int{5}},
+	}
+	for _, tt := range tests {
+		if got := sorted(tt.got); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if a.Len() != 4 || b.Len() != 3 {
+		t.Error("operands should not be modified")
+	}
+}
+
+func TestSet_Relations(t *testing.T) {
+	a := wtype.NewSet(1, 2)
+	b := wtype.NewSafeSet(1, 2, 3)
+	c := wtype.NewSet(4)
+
+	if !a.IsSubsetOf(b) || b.IsSubsetOf(a) {
+		t.Error("expected a to be a proper subset of b")
+	}
+	if !b.IsSupersetOf(a) || a.IsSupersetOf(b) {
+		t.Error("expected b to be a proper superset of a")
+	}
+	if !a.IsDisjoint(c) || a.IsDisjoint(b) || b.IsDisjoint(a) {
+		t.Error("unexpected IsDisjoint result")
+	}
+	if !a.Equal(wtype.NewSafeSet(2, 1)) || a.Equal(b) || !b.Equal(b) {
+		t.Error("unexpected Equal result")
+	}
+	if !wtype.NewSet[int]().IsSubsetOf(c) || !wtype.SetEqual[int](wtype.NewSet[int](), wtype.NewSafeSet[int]()) {
+		t.Error("the empty set should be a subset of every set")
+	}
+}
+
+func TestSafeSet_AlgebraConcurrent(t *testing.T) {
+	a := wtype.NewSafeSet[int]()
+	b := wtype.NewSafeSet[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Add(j)
+				b.Add(j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Union(b)
+				b.Intersection(a)
+			}
+		}()
+	}
+	wg.Wait()
+	if !a.Equal(b) {
+		t.Error("expected equal sets")
+	}
+}
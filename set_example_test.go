@@ -317,3 +317,18 @@ func ExampleSet_emptySet() {
 	// Ranging over empty set:
 	// Range completed
 }
+
+// ExampleSet_Union demonstrates the set algebra methods
+func ExampleSet_Union() {
+	admin := wtype.NewSet("read", "write", "delete")
+	editor := wtype.NewSafeSet("read", "write")
+
+	fmt.Println(admin.Union(editor).SortValues(func(a, b string) bool { return a < b }))
+	fmt.Println(admin.Difference(editor).Values())
+	fmt.Println(editor.IsSubsetOf(admin))
+
+	// Output:
+	// [delete read write]
+	// [delete]
+	// true
+}
@@ -2,6 +2,8 @@ package wtype_test
 
 import (
 	"encoding/json"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/wuchieh/wtype"
@@ -102,3 +104,28 @@ func TestSet_JSON(t *testing.T) {
 		return false
 	})
 }
+
+func TestSet_All(t *testing.T) {
+	s := wtype.SetFrom(slices.Values([]int{3, 1, 2, 3}))
+	if s.Len() != 3 {
+		t.Errorf("expected 3 elements, got %d", s.Len())
+	}
+	got := slices.Sorted(s.All())
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+
+	n := 0
+	for range s.All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected the loop to stop after 1 element, got %d", n)
+	}
+
+	keys := wtype.SetFrom(maps.Keys(map[string]int{"a": 1, "b": 2}))
+	if !keys.Contains("a") || !keys.Contains("b") {
+		t.Error("expected the keys of the map")
+	}
+}
@@ -0,0 +1,192 @@
+package wtype
+
+import (
+	"hash/maphash"
+	"time"
+)
+
+// ShardedCache is a keyed cache split into shards, each a KeyedCache with its own lock.
+//
+//	Keys are hashed to a shard, so goroutines working on different keys rarely wait for each other.
+//	Capacity and size budgets are divided evenly between the shards and enforced per shard,
+//	so an entry may be evicted before the cache as a whole is full.
+type ShardedCache[K comparable, V any] struct {
+	shards []*KeyedCache[K, V]
+	seed   maphash.Seed
+}
+
+// shard returns the shard of key.
+func (c *ShardedCache[K, V]) shard(key K) *KeyedCache[K, V] {
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+// Shards returns the number of shards.
+func (c *ShardedCache[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+// SetDuration sets the default duration of entries set after this call.
+func (c *ShardedCache[K, V]) SetDuration(d time.Duration) {
+	for _, s := range c.shards {
+		s.SetDuration(d)
+	}
+}
+
+// Set sets the data of key using the default duration.
+func (c *ShardedCache[K, V]) Set(key K, data V) {
+	c.shard(key).Set(key, data)
+}
+
+// SetWithDuration sets the data of key with its own duration.
+//
+//	If d is 0, the entry never expires. If d is negative, it expires at once.
+func (c *ShardedCache[K, V]) SetWithDuration(key K, data V, d time.Duration) {
+	c.shard(key).SetWithDuration(key, data, d)
+}
+
+// SetWithTags sets the data of key using the default duration and attaches tags to it.
+func (c *ShardedCache[K, V]) SetWithTags(key K, data V, tags ...string) {
+	c.shard(key).SetWithTags(key, data, tags...)
+}
+
+// SetWithDurationAndTags sets the data of key with its own duration and attaches tags to it.
+func (c *ShardedCache[K, V]) SetWithDurationAndTags(key K, data V, d time.Duration, tags ...string) {
+	c.shard(key).SetWithDurationAndTags(key, data, d, tags...)
+}
+
+// InvalidateTag removes every entry tagged with tag and returns how many were removed.
+//
+//	Each shard is invalidated atomically, but not all shards at once.
+func (c *ShardedCache[K, V]) InvalidateTag(tag string) int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.InvalidateTag(tag)
+	}
+	return n
+}
+
+// Get gets the data of key.
+func (c *ShardedCache[K, V]) Get(key K) (V, bool) {
+	return c.shard(key).Get(key)
+}
+
+// TTL returns the time left before key expires, or 0 if it never expires.
+//
+//	It returns false if the key does not exist.
+func (c *ShardedCache[K, V]) TTL(key K) (time.Duration, bool) {
+	return c.shard(key).TTL(key)
+}
+
+// Delete removes key from the cache.
+func (c *ShardedCache[K, V]) Delete(key K) {
+	c.shard(key).Delete(key)
+}
+
+// ResetTimer resets the timer of key.
+//
+//	It returns false if the key does not exist.
+func (c *ShardedCache[K, V]) ResetTimer(key K) bool {
+	return c.shard(key).ResetTimer(key)
+}
+
+// StopTimer stops the timer of key.
+//
+//	It returns false if the key does not exist.
+func (c *ShardedCache[K, V]) StopTimer(key K) bool {
+	return c.shard(key).StopTimer(key)
+}
+
+// Len returns the number of entries in the cache.
+func (c *ShardedCache[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// SetMaxSize limits the total size of the entries, as reported by sizer, to max.
+//
+//	The budget is split evenly between the shards, so a shard may evict before the whole cache is full.
+//	See KeyedCache.SetMaxSize.
+func (c *ShardedCache[K, V]) SetMaxSize(max FileSize, sizer func(V) FileSize) {
+	n := FileSize(len(c.shards))
+	if max > 0 {
+		max = (max + n - 1) / n
+	}
+	for _, s := range c.shards {
+		s.SetMaxSize(max, sizer)
+	}
+}
+
+// Size returns the total size of the entries, as reported by the sizer.
+func (c *ShardedCache[K, V]) Size() FileSize {
+	var n FileSize
+	for _, s := range c.shards {
+		n += s.Size()
+	}
+	return n
+}
+
+// Range iterates over the cache shard by shard and calls f for each entry.
+//
+//	If f returns false, the iteration stops.
+func (c *ShardedCache[K, V]) Range(f func(key K, value V) bool) {
+	next := true
+	for _, s := range c.shards {
+		s.Range(func(key K, value V) bool {
+			next = f(key, value)
+			return next
+		})
+		if !next {
+			return
+		}
+	}
+}
+
+// Clear removes all entries from the cache.
+func (c *ShardedCache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+	}
+}
+
+// DeleteExpired removes expired entries.
+//
+//	It implements Expirer so the cache can be registered with a Janitor.
+func (c *ShardedCache[K, V]) DeleteExpired() {
+	for _, s := range c.shards {
+		s.DeleteExpired()
+	}
+}
+
+// Stats returns the sum of the counters of the shards.
+func (c *ShardedCache[K, V]) Stats() CacheStats {
+	var stats CacheStats
+	for _, s := range c.shards {
+		stats = stats.add(s.Stats())
+	}
+	return stats
+}
+
+// NewShardedCache creates a new sharded keyed cache.
+//
+//	d is the default duration of each entry. If d is 0, entries never expire.
+//	WithShards sets the number of shards; the other options configure every shard like NewKeyedCache,
+//	except that the capacity given by WithCapacity is split evenly between the shards.
+func NewShardedCache[K comparable, V any](d time.Duration, opts ...ShardedCacheOption) *ShardedCache[K, V] {
+	o := newCacheOptions(opts)
+	n := max(o.shards, 1)
+	if o.capacity > 0 {
+		o.capacity = (o.capacity + n - 1) / n
+	}
+
+	c := &ShardedCache[K, V]{
+		shards: make([]*KeyedCache[K, V], n),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range c.shards {
+		c.shards[i] = newKeyedCache[K, V](d, o)
+	}
+	return c
+}
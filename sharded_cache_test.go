@@ -0,0 +1,153 @@
+package wtype_test
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestShardedCache(t *testing.T) {
+	c := wtype.NewShardedCache[string, int](time.Minute, wtype.WithShards(4))
+	if c.Shards() != 4 {
+		t.Errorf("expected 4 shards, got %d", c.Shards())
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	if c.Len() != 100 {
+		t.Errorf("expected 100 entries, got %d", c.Len())
+	}
+	for i := 0; i < 100; i++ {
+		if v, ok := c.Get(strconv.Itoa(i)); !ok || v != i {
+			t.Fatalf("expected (%d, true), got (%d, %v)", i, v, ok)
+		}
+	}
+
+	n := 0
+	c.Range(func(string, int) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("expected Range to stop after 10 entries, got %d", n)
+	}
+
+	c.SetWithTags("x", 1, "t")
+	c.SetWithTags("y", 2, "t")
+	if n := c.InvalidateTag("t"); n != 2 {
+		t.Errorf("expected 2 entries invalidated, got %d", n)
+	}
+
+	c.Delete("0")
+	if _, ok := c.Get("0"); ok {
+		t.Error("expected 0 to be deleted")
+	}
+	if s := c.Stats(); s.Hits != 100 || s.Misses != 1 || s.Sets != 102 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("expected empty cache, got %d", c.Len())
+	}
+}
+
+func TestShardedCache_Expiration(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	c := wtype.NewShardedCache[int, int](time.Second, wtype.WithClock(clock))
+	c.Set(1, 1)
+	c.SetWithDuration(2, 2, 0)
+	clock.Advance(2 * time.Second)
+	c.DeleteExpired()
+	if c.Len() != 1 {
+		t.Errorf("expected 1 entry left, got %d", c.Len())
+	}
+	if _, ok := c.Get(2); !ok {
+		t.Error("expected 2 to never expire")
+	}
+}
+
+func TestShardedCache_Capacity(t *testing.T) {
+	c := wtype.NewShardedCache[int, int](0, wtype.WithShards(4), wtype.WithCapacity(100))
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i)
+	}
+	if n := c.Len(); n > 100 {
+		t.Errorf("expected at most 100 entries, got %d", n)
+	}
+}
+
+func TestShardedCache_Concurrent(t *testing.T) {
+	c := wtype.NewShardedCache[int, int](time.Minute)
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Set(i, i)
+				c.Get(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() != 1000 {
+		t.Errorf("expected 1000 entries, got %d", c.Len())
+	}
+}
+
+// benchmarkKeyedParallel runs a mix of 3 reads to 1 write from 64 goroutines per CPU.
+func benchmarkKeyedParallel(b *testing.B, get func(int) bool, set func(int, int)) {
+	const keys = 1 << 12
+	for i := 0; i < keys; i++ {
+		set(i, i)
+	}
+	var seq atomic.Int64
+	b.SetParallelism(64)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seq.Add(1)) * 7919
+		for pb.Next() {
+			i++
+			k := i % keys
+			if i%4 == 0 {
+				set(k, i)
+			} else {
+				get(k)
+			}
+		}
+	})
+}
+
+func BenchmarkKeyedCache_Parallel(b *testing.B) {
+	c := wtype.NewKeyedCache[int, int](time.Minute)
+	benchmarkKeyedParallel(b, func(k int) bool {
+		_, ok := c.Get(k)
+		return ok
+	}, c.Set)
+}
+
+func BenchmarkShardedCache_Parallel(b *testing.B) {
+	for _, shards := range []int{4, 16, 64} {
+		b.Run(strconv.Itoa(shards), func(b *testing.B) {
+			c := wtype.NewShardedCache[int, int](time.Minute, wtype.WithShards(shards))
+			benchmarkKeyedParallel(b, func(k int) bool {
+				_, ok := c.Get(k)
+				return ok
+			}, c.Set)
+		})
+	}
+}
+
+func BenchmarkSyncMap_Parallel(b *testing.B) {
+	var m wtype.SyncMap[int, int]
+	benchmarkKeyedParallel(b, func(k int) bool {
+		_, ok := m.Load(k)
+		return ok
+	}, m.Store)
+}
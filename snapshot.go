@@ -0,0 +1,153 @@
+package wtype
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// SnapshotFormat is the encoding used to dump and restore cache contents.
+type SnapshotFormat int
+
+const (
+	// SnapshotJSON encodes snapshots with encoding/json.
+	SnapshotJSON SnapshotFormat = iota
+	// SnapshotGob encodes snapshots with encoding/gob.
+	//
+	//	Interface values stored in the cache must be registered with gob.Register.
+	SnapshotGob
+)
+
+// ErrSnapshotFormat is returned for an unknown SnapshotFormat.
+var ErrSnapshotFormat = errors.New("wtype: unknown snapshot format")
+
+// encodeSnapshot writes v to w in format f.
+func encodeSnapshot(w io.Writer, f SnapshotFormat, v any) error {
+	switch f {
+	case SnapshotJSON:
+		return json.NewEncoder(w).Encode(v)
+	case SnapshotGob:
+		return gob.NewEncoder(w).Encode(v)
+	default:
+		return ErrSnapshotFormat
+	}
+}
+
+// decodeSnapshot reads v from r in format f.
+func decodeSnapshot(r io.Reader, f SnapshotFormat, v any) error {
+	switch f {
+	case SnapshotJSON:
+		return json.NewDecoder(r).Decode(v)
+	case SnapshotGob:
+		return gob.NewDecoder(r).Decode(v)
+	default:
+		return ErrSnapshotFormat
+	}
+}
+
+// cacheSnapshot is the encoded form of a SafeCache.
+type cacheSnapshot[T any] struct {
+	Valid    bool // false if the cache held no data
+	Data     T
+	Duration time.Duration
+	Created  time.Time
+	ExpireAt time.Time // zero if the data never expires
+}
+
+// keyedSnapshotEntry is the encoded form of a KeyedCache entry.
+type keyedSnapshotEntry[K comparable, V any] struct {
+	Key      K
+	Data     V
+	Duration time.Duration
+	Created  time.Time
+	ExpireAt time.Time // zero if the entry never expires
+	Tags     []string  `json:",omitempty"`
+}
+
+// Snapshot writes the data of the cache and its expiry time to w.
+func (s *SafeCache[T]) Snapshot(w io.Writer, f SnapshotFormat) error {
+	s.mutex.Lock()
+	data := s.cache.get()
+	snap := cacheSnapshot[T]{
+		Valid:    s.cache.ok,
+		Data:     data,
+		Duration: s.cache.d,
+		Created:  s.cache.created,
+		ExpireAt: s.freshUntil(),
+	}
+	s.unlock()
+	return encodeSnapshot(w, f, snap)
+}
+
+// Restore reads a snapshot written by Snapshot from r.
+//
+//	The data keeps the expiry time it had when the snapshot was taken, and is ignored if
+//	that time has already passed. The duration of the cache is not changed. In refresh-ahead mode,
+//	the restored data is refreshed ahead of that time and may be served stale as if it had been set.
+//	Snapshots do not include the staleness allowance, so it is only applied on restore.
+func (s *SafeCache[T]) Restore(r io.Reader, f SnapshotFormat) error {
+	var snap cacheSnapshot[T]
+	if err := decodeSnapshot(r, f, &snap); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.unlock()
+	if !snap.Valid || expired(snap.ExpireAt, s.cache.now()) {
+		return nil
+	}
+	s.cache.set(snap.Data)
+	s.cache.created = snap.Created
+	s.cache.expireAt = snap.ExpireAt
+	s.armRefreshAt(snap.ExpireAt)
+	return nil
+}
+
+// Snapshot writes every entry of the cache and its expiry time to w.
+func (c *KeyedCache[K, V]) Snapshot(w io.Writer, f SnapshotFormat) error {
+	c.mutex.RLock()
+	now := c.clock.Now()
+	entries := make([]keyedSnapshotEntry[K, V], 0, len(c.m))
+	for k, e := range c.m {
+		if expired(e.expireAt, now) {
+			continue
+		}
+		entries = append(entries, keyedSnapshotEntry[K, V]{
+			Key:      k,
+			Data:     e.data,
+			Duration: e.d,
+			Created:  e.created,
+			ExpireAt: e.expireAt,
+			Tags:     e.tags,
+		})
+	}
+	c.mutex.RUnlock()
+	return encodeSnapshot(w, f, entries)
+}
+
+// Restore reads a snapshot written by Snapshot from r and adds its entries to the cache.
+//
+//	Entries keep the expiry time they had when the snapshot was taken, and those that have
+//	already expired are skipped. Existing entries with the same key are replaced.
+func (c *KeyedCache[K, V]) Restore(r io.Reader, f SnapshotFormat) error {
+	var entries []keyedSnapshotEntry[K, V]
+	if err := decodeSnapshot(r, f, &entries); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.clock.Now()
+	for _, s := range entries {
+		if expired(s.ExpireAt, now) {
+			continue
+		}
+		if e := c.setTagged(s.Key, s.Data, s.Duration, s.Tags); e != nil {
+			e.created = s.Created
+			e.expireAt = s.ExpireAt
+		}
+	}
+	return nil
+}
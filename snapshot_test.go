@@ -0,0 +1,141 @@
+package wtype_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func TestSafeCache_Snapshot(t *testing.T) {
+	for _, f := range []wtype.SnapshotFormat{wtype.SnapshotJSON, wtype.SnapshotGob} {
+		clock := wtype.NewFakeClock(clockStart)
+		src := wtype.NewSafeCacheWithOptions[string](time.Hour, wtype.WithClock(clock))
+		src.Set("data")
+		clock.Advance(40 * time.Minute)
+
+		buf := bytes.Buffer{}
+		if err := src.Snapshot(&buf, f); err != nil {
+			t.Fatalf("format %d: %v", f, err)
+		}
+
+		dst := wtype.NewSafeCacheWithOptions[string](time.Hour, wtype.WithClock(clock))
+		if err := dst.Restore(&buf, f); err != nil {
+			t.Fatalf("format %d: %v", f, err)
+		}
+		if dst.Get() != "data" {
+			t.Errorf("format %d: expected restored data, got %q", f, dst.Get())
+		}
+
+		clock.Advance(20 * time.Minute)
+		if dst.Get() != "" {
+			t.Errorf("format %d: restored data should keep its original expiry", f)
+		}
+	}
+}
+
+func TestSafeCache_SnapshotExpired(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	src := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+	src.Set(1)
+
+	buf := bytes.Buffer{}
+	if err := src.Snapshot(&buf, wtype.SnapshotJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	dst := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+	if err := dst.Restore(&buf, wtype.SnapshotJSON); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Get() != 0 {
+		t.Errorf("expired data should not be restored, got %d", dst.Get())
+	}
+}
+
+func TestKeyedCache_Snapshot(t *testing.T) {
+	for _, f := range []wtype.SnapshotFormat{wtype.SnapshotJSON, wtype.SnapshotGob} {
+		clock := wtype.NewFakeClock(clockStart)
+		src := wtype.NewKeyedCache[string, int](time.Hour, wtype.WithClock(clock))
+		src.Set("a", 1)
+		src.SetWithDuration("b", 2, 10*time.Minute)
+		src.SetWithDuration("forever", 3, 0)
+		clock.Advance(5 * time.Minute)
+
+		buf := bytes.Buffer{}
+		if err := src.Snapshot(&buf, f); err != nil {
+			t.Fatalf("format %d: %v", f, err)
+		}
+
+		// the process restarts later; b expires while it is down
+		clock.Advance(10 * time.Minute)
+		dst := wtype.NewKeyedCache[string, int](time.Minute, wtype.WithClock(clock))
+		if err := dst.Restore(&buf, f); err != nil {
+			t.Fatalf("format %d: %v", f, err)
+		}
+		if dst.Len() != 2 {
+			t.Errorf("format %d: expected 2 restored entries, got %d", f, dst.Len())
+		}
+		if _, ok := dst.Get("b"); ok {
+			t.Errorf("format %d: b should have expired", f)
+		}
+
+		clock.Advance(44 * time.Minute)
+		if v, ok := dst.Get("a"); !ok || v != 1 {
+			t.Errorf("format %d: a should keep its original expiry", f)
+		}
+		clock.Advance(time.Minute)
+		if _, ok := dst.Get("a"); ok {
+			t.Errorf("format %d: a should have expired", f)
+		}
+		if v, ok := dst.Get("forever"); !ok || v != 3 {
+			t.Errorf("format %d: forever should never expire", f)
+		}
+	}
+}
+
+func TestSnapshot_UnknownFormat(t *testing.T) {
+	c := wtype.NewSafeCache(0, 1)
+	if err := c.Snapshot(&bytes.Buffer{}, wtype.SnapshotFormat(99)); !errors.Is(err, wtype.ErrSnapshotFormat) {
+		t.Errorf("expected ErrSnapshotFormat, got %v", err)
+	}
+}
+
+func TestSafeCache_RestoreRefresh(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	src := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+	src.SetRefresh(func(old int) (int, error) { return old, nil }, 10*time.Second, time.Hour)
+	src.Set(1)
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf, wtype.SnapshotJSON); err != nil {
+		t.Fatal(err)
+	}
+	src.StopTimer()
+
+	errFailed := errors.New("failed")
+	var refreshed []int
+	dst := wtype.NewSafeCacheWithOptions[int](time.Minute, wtype.WithClock(clock))
+	dst.SetRefresh(func(old int) (int, error) {
+		refreshed = append(refreshed, old)
+		return 0, errFailed
+	}, 10*time.Second, 30*time.Second)
+	if err := dst.Restore(&buf, wtype.SnapshotJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(50 * time.Second) // ahead of the restored expiry
+	if len(refreshed) != 1 || refreshed[0] != 1 {
+		t.Fatalf("expected the restored value to be refreshed, got %v", refreshed)
+	}
+	clock.Advance(30 * time.Second) // past the expiry, within the staleness allowance
+	if dst.Get() != 1 {
+		t.Errorf("expected the stale value to be served, got %d", dst.Get())
+	}
+	clock.Advance(time.Minute)
+	if dst.Get() != 0 {
+		t.Errorf("expected the value to expire after max staleness, got %d", dst.Get())
+	}
+}
@@ -0,0 +1,340 @@
+package wtype
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"iter"
+	"math/rand/v2"
+)
+
+// sortedNode is a node of the treap behind SortedSet.
+type sortedNode[T any] struct {
+	v     T
+	pri   uint64
+	size  int
+	left  *sortedNode[T]
+	right *sortedNode[T]
+}
+
+// sizeOf returns the number of elements in the subtree of n.
+func (n *sortedNode[T]) sizeOf() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// update recomputes the size of n from its children.
+func (n *sortedNode[T]) update() {
+	n.size = 1 + n.left.sizeOf() + n.right.sizeOf()
+}
+
+// SortedSet is a generic, non-thread-safe set that keeps its elements sorted.
+//
+//	It is a treap, a randomized balanced binary tree, so Add, Remove, Contains, Floor, Ceiling,
+//	Rank and At take logarithmic time on average.
+type SortedSet[T comparable] struct {
+	root *sortedNode[T]
+	cmp  func(a, b T) int
+}
+
+// MarshalJSON implementation json.Marshal
+//
+//	The elements are written in ascending order.
+func (s SortedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Get())
+}
+
+// UnmarshalJSON implementation json.Unmarshal
+//
+//	The set must have been created by NewSortedSet or NewSortedSetFunc, so it has a comparator.
+func (s *SortedSet[T]) UnmarshalJSON(bytes []byte) error {
+	if s.cmp == nil {
+		return errors.New("wtype: SortedSet has no comparator, create it with NewSortedSet or NewSortedSetFunc")
+	}
+	var data []T
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return err
+	}
+	s.Clear()
+	for _, v := range data {
+		s.Add(v)
+	}
+	return nil
+}
+
+// split splits the subtree of n into the elements less than v and the others.
+func (s *SortedSet[T]) split(n *sortedNode[T], v T) (*sortedNode[T], *sortedNode[T]) {
+	if n == nil {
+		return nil, nil
+	}
+	if s.cmp(n.v, v) < 0 {
+		l, r := s.split(n.right, v)
+		n.right = l
+		n.update()
+		return n, r
+	}
+	l, r := s.split(n.left, v)
+	n.left = r
+	n.update()
+	return l, n
+}
+
+// merge joins two subtrees where every element of l is less than every element of r.
+func (s *SortedSet[T]) merge(l, r *sortedNode[T]) *sortedNode[T] {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	if l.pri > r.pri {
+		l.right = s.merge(l.right, r)
+		l.update()
+		return l
+	}
+	r.left = s.merge(l, r.left)
+	r.update()
+	return r
+}
+
+// remove removes v from the subtree of n and returns the new subtree.
+func (s *SortedSet[T]) remove(n *sortedNode[T], v T) *sortedNode[T] {
+	if n == nil {
+		return nil
+	}
+	switch c := s.cmp(v, n.v); {
+	case c < 0:
+		n.left = s.remove(n.left, v)
+	case c > 0:
+		n.right = s.remove(n.right, v)
+	default:
+		return s.merge(n.left, n.right)
+	}
+	n.update()
+	return n
+}
+
+// Values is an alias for Get.
+func (s *SortedSet[T]) Values() []T {
+	return s.Get()
+}
+
+// Add adds an element to the set.
+func (s *SortedSet[T]) Add(data T) {
+	if s.Contains(data) {
+		return
+	}
+	l, r := s.split(s.root, data)
+	n := &sortedNode[T]{v: data, pri: rand.Uint64(), size: 1}
+	s.root = s.merge(s.merge(l, n), r)
+}
+
+// Get returns all elements in the set as a slice, in ascending order.
+func (s *SortedSet[T]) Get() []T {
+	ret := make([]T, 0, s.Len())
+	s.Range(func(v T) bool {
+		ret = append(ret, v)
+		return true
+	})
+	return ret
+}
+
+// Len returns the number of elements in the set.
+func (s *SortedSet[T]) Len() int {
+	return s.root.sizeOf()
+}
+
+// Remove removes an element from the set.
+func (s *SortedSet[T]) Remove(data T) {
+	s.root = s.remove(s.root, data)
+}
+
+// Contains checks if an element exists in the set.
+func (s *SortedSet[T]) Contains(data T) bool {
+	for n := s.root; n != nil; {
+		switch c := s.cmp(data, n.v); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all elements from the set.
+func (s *SortedSet[T]) Clear() {
+	s.root = nil
+}
+
+// Range iterates over the set in ascending order and calls f for each element.
+//
+//	If f returns false, the iteration stops.
+func (s *SortedSet[T]) Range(f func(T) bool) {
+	var stack []*sortedNode[T]
+	for n := s.root; n != nil || len(stack) > 0; n = n.right {
+		for ; n != nil; n = n.left {
+			stack = append(stack, n)
+		}
+		n = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !f(n.v) {
+			return
+		}
+	}
+}
+
+// All returns an iterator over the elements of the set in ascending order.
+func (s *SortedSet[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// Between returns the elements from lo to hi inclusive, in ascending order.
+//
+//	It is the range query of the set; Range is the ISet iteration.
+func (s *SortedSet[T]) Between(lo, hi T) []T {
+	var ret []T
+	var walk func(n *sortedNode[T])
+	walk = func(n *sortedNode[T]) {
+		if n == nil {
+			return
+		}
+		aboveLo, belowHi := s.cmp(n.v, lo) >= 0, s.cmp(n.v, hi) <= 0
+		if aboveLo {
+			walk(n.left)
+		}
+		if aboveLo && belowHi {
+			ret = append(ret, n.v)
+		}
+		if belowHi {
+			walk(n.right)
+		}
+	}
+	walk(s.root)
+	return ret
+}
+
+// Min returns the smallest element.
+//
+//	It returns false if the set is empty.
+func (s *SortedSet[T]) Min() (T, bool) {
+	n := s.root
+	if n == nil {
+		return *new(T), false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.v, true
+}
+
+// Max returns the largest element.
+//
+//	It returns false if the set is empty.
+func (s *SortedSet[T]) Max() (T, bool) {
+	n := s.root
+	if n == nil {
+		return *new(T), false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.v, true
+}
+
+// Floor returns the largest element less than or equal to v.
+//
+//	It returns false if there is no such element.
+func (s *SortedSet[T]) Floor(v T) (T, bool) {
+	var ret *sortedNode[T]
+	for n := s.root; n != nil; {
+		if c := s.cmp(n.v, v); c == 0 {
+			return n.v, true
+		} else if c < 0 {
+			ret, n = n, n.right
+		} else {
+			n = n.left
+		}
+	}
+	if ret == nil {
+		return *new(T), false
+	}
+	return ret.v, true
+}
+
+// Ceiling returns the smallest element greater than or equal to v.
+//
+//	It returns false if there is no such element.
+func (s *SortedSet[T]) Ceiling(v T) (T, bool) {
+	var ret *sortedNode[T]
+	for n := s.root; n != nil; {
+		if c := s.cmp(n.v, v); c == 0 {
+			return n.v, true
+		} else if c > 0 {
+			ret, n = n, n.left
+		} else {
+			n = n.right
+		}
+	}
+	if ret == nil {
+		return *new(T), false
+	}
+	return ret.v, true
+}
+
+// Rank returns the number of elements less than v, which is the index of v if it is in the set.
+func (s *SortedSet[T]) Rank(v T) int {
+	rank := 0
+	for n := s.root; n != nil; {
+		if s.cmp(n.v, v) < 0 {
+			rank += n.left.sizeOf() + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return rank
+}
+
+// At returns the element at index i in ascending order.
+//
+//	It returns false if i is out of range.
+func (s *SortedSet[T]) At(i int) (T, bool) {
+	if i < 0 || i >= s.Len() {
+		return *new(T), false
+	}
+	n := s.root
+	for {
+		l := n.left.sizeOf()
+		switch {
+		case i < l:
+			n = n.left
+		case i > l:
+			i -= l + 1
+			n = n.right
+		default:
+			return n.v, true
+		}
+	}
+}
+
+// NewSortedSet creates a new SortedSet ordered by cmp.Compare.
+func NewSortedSet[T cmp.Ordered](val ...T) *SortedSet[T] {
+	return NewSortedSetFunc(cmp.Compare[T], val...)
+}
+
+// NewSortedSetFunc creates a new SortedSet ordered by compare.
+//
+//	compare returns a negative number if a < b, a positive number if a > b and 0 if they are equal,
+//	like cmp.Compare. Elements it considers equal are treated as the same element.
+func NewSortedSetFunc[T comparable](compare func(a, b T) int, val ...T) *SortedSet[T] {
+	s := &SortedSet[T]{cmp: compare}
+	for _, v := range val {
+		s.Add(v)
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package wtype_test
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wuchieh/wtype"
+)
+
+var _ wtype.ISet[int] = (*wtype.SortedSet[int])(nil)
+
+func TestSortedSet(t *testing.T) {
+	s := wtype.NewSortedSet(5, 1, 9, 3, 7, 3)
+	if got := s.Values(); !slices.Equal(got, []int{1, 3, 5, 7, 9}) {
+		t.Errorf("expected [1 3 5 7 9], got %v", got)
+	}
+	if v, ok := s.Min(); !ok || v != 1 {
+		t.Errorf("expected Min 1, got (%d, %v)", v, ok)
+	}
+	if v, ok := s.Max(); !ok || v != 9 {
+		t.Errorf("expected Max 9, got (%d, %v)", v, ok)
+	}
+	if v, ok := s.Floor(6); !ok || v != 5 {
+		t.Errorf("expected Floor(6) 5, got (%d, %v)", v, ok)
+	}
+	if _, ok := s.Floor(0); ok {
+		t.Error("expected no Floor(0)")
+	}
+	if v, ok := s.Ceiling(6); !ok || v != 7 {
+		t.Errorf("expected Ceiling(6) 7, got (%d, %v)", v, ok)
+	}
+	if _, ok := s.Ceiling(10); ok {
+		t.Error("expected no Ceiling(10)")
+	}
+	if got := s.Between(3, 8); !slices.Equal(got, []int{3, 5, 7}) {
+		t.Errorf("expected Between(3, 8) [3 5 7], got %v", got)
+	}
+	if s.Rank(7) != 3 || s.Rank(6) != 3 || s.Rank(0) != 0 {
+		t.Errorf("unexpected Rank results %d, %d, %d", s.Rank(7), s.Rank(6), s.Rank(0))
+	}
+	if v, ok := s.At(2); !ok || v != 5 {
+		t.Errorf("expected At(2) 5, got (%d, %v)", v, ok)
+	}
+	if _, ok := s.At(5); ok {
+		t.Error("expected At(5) to be out of range")
+	}
+
+	s.Remove(5)
+	s.Remove(42)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []int{1, 3, 7, 9}) {
+		t.Errorf("expected [1 3 7 9], got %v", got)
+	}
+
+	s.Clear()
+	if _, ok := s.Min(); ok || s.Len() != 0 {
+		t.Error("expected an empty set")
+	}
+}
+
+func TestSortedSet_Func(t *testing.T) {
+	s := wtype.NewSortedSetFunc(func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}, "b", "A", "c")
+	if got := s.Values(); !slices.Equal(got, []string{"A", "b", "c"}) {
+		t.Errorf("expected [A b c], got %v", got)
+	}
+	if !s.Contains("a") {
+		t.Error("expected the comparator to treat a and A as equal")
+	}
+}
+
+func TestSortedSet_Random(t *testing.T) {
+	s := wtype.NewSortedSet[int]()
+	ref := map[int]bool{}
+	for i := 0; i < 2000; i++ {
+		v := rand.IntN(500)
+		if rand.IntN(3) == 0 {
+			s.Remove(v)
+			delete(ref, v)
+		} else {
+			s.Add(v)
+			ref[v] = true
+		}
+	}
+
+	var want []int
+	for v := range ref {
+		want = append(want, v)
+	}
+	slices.Sort(want)
+	if got := s.Values(); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i, v := range want {
+		if got, _ := s.At(i); got != v {
+			t.Fatalf("At(%d): expected %d, got %d", i, v, got)
+		}
+		if r := s.Rank(v); r != i {
+			t.Fatalf("Rank(%d): expected %d, got %d", v, i, r)
+		}
+	}
+}
+
+func TestSortedSet_JSON(t *testing.T) {
+	b, err := json.Marshal(wtype.NewSortedSet(3, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[1,2,3]" {
+		t.Errorf("expected [1,2,3], got %s", b)
+	}
+
+	s := wtype.NewSortedSet[int]()
+	if err := json.Unmarshal([]byte("[9,4,4,6]"), s); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Values(); !slices.Equal(got, []int{4, 6, 9}) {
+		t.Errorf("expected [4 6 9], got %v", got)
+	}
+
+	var zero wtype.SortedSet[int]
+	if err := json.Unmarshal([]byte("[1]"), &zero); err == nil {
+		t.Error("expected an error without a comparator")
+	}
+}
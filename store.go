@@ -0,0 +1,214 @@
+package wtype
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store is a key-value backend with per-key TTLs, used as the second tier of a TieredCache.
+//
+//	A ttl of 0 means the value never expires. Implementations must be safe for concurrent use.
+type Store[K comparable, V any] interface {
+	// Get returns the value of key and whether it was found.
+	Get(ctx context.Context, key K) (V, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key K, value V, ttl time.Duration) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key K) error
+	// TTL returns the time left before key expires, 0 if it never expires, and whether it was found.
+	TTL(ctx context.Context, key K) (time.Duration, bool, error)
+}
+
+// MemoryStore is an in-memory Store backed by a KeyedCache.
+type MemoryStore[K comparable, V any] struct {
+	cache *KeyedCache[K, V]
+}
+
+// Get implements Store.
+func (s *MemoryStore[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	v, ok := s.cache.Get(key)
+	return v, ok, nil
+}
+
+// Set implements Store.
+func (s *MemoryStore[K, V]) Set(_ context.Context, key K, value V, ttl time.Duration) error {
+	s.cache.SetWithDuration(key, value, ttl)
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore[K, V]) Delete(_ context.Context, key K) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+// TTL implements Store.
+func (s *MemoryStore[K, V]) TTL(_ context.Context, key K) (time.Duration, bool, error) {
+	ttl, ok := s.cache.TTL(key)
+	return ttl, ok, nil
+}
+
+// NewMemoryStore creates an in-memory store.
+//
+//	opts configure the underlying KeyedCache, such as WithCapacity and WithClock.
+func NewMemoryStore[K comparable, V any](opts ...KeyedCacheOption) *MemoryStore[K, V] {
+	return &MemoryStore[K, V]{
+		cache: NewKeyedCache[K, V](0, opts...),
+	}
+}
+
+// fileStoreEntry is the content of a FileStore file.
+type fileStoreEntry[V any] struct {
+	ExpireAt time.Time `json:"expire_at"` // zero if the value never expires
+	Value    V         `json:"value"`
+}
+
+// FileStore is a Store that keeps one JSON file per key in a directory.
+//
+//	File names are the SHA-256 of the key formatted with %#v, so keys must format uniquely.
+//	Expired files are removed when they are read, unless a Set has replaced them since.
+type FileStore[K comparable, V any] struct {
+	dir   string
+	clock Clock
+}
+
+// path returns the file of key.
+func (s *FileStore[K, V]) path(key K) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%#v", key)))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// read returns the entry of key, removing it if it has expired.
+func (s *FileStore[K, V]) read(key K) (fileStoreEntry[V], bool, error) {
+	var e fileStoreEntry[V]
+	p := s.path(key)
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return e, false, nil
+	}
+	if err != nil {
+		return e, false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return e, false, err
+	}
+	if err := json.NewDecoder(f).Decode(&e); err != nil {
+		return e, false, err
+	}
+	if expired(e.ExpireAt, s.clock.Now()) {
+		return e, false, s.removeIfSame(p, info)
+	}
+	return e, true, nil
+}
+
+// removeIfSame removes p only if it is still the file described by info.
+//
+//	A Set renames a new file onto p, so a fresh value written after the read is kept.
+func (s *FileStore[K, V]) removeIfSame(p string, info fs.FileInfo) error {
+	cur, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(info, cur) {
+		return nil
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Get implements Store.
+func (s *FileStore[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return *new(V), false, err
+	}
+	e, ok, err := s.read(key)
+	return e.Value, ok, err
+}
+
+// Set implements Store.
+//
+//	The file is written to a temporary name and renamed, so readers never see a partial value.
+func (s *FileStore[K, V]) Set(ctx context.Context, key K, value V, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(fileStoreEntry[V]{
+		ExpireAt: deadline(s.clock.Now(), ttl),
+		Value:    value,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// Delete implements Store.
+func (s *FileStore[K, V]) Delete(ctx context.Context, key K) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// TTL implements Store.
+func (s *FileStore[K, V]) TTL(ctx context.Context, key K) (time.Duration, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, err
+	}
+	e, ok, err := s.read(key)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+	if e.ExpireAt.IsZero() {
+		return 0, true, nil
+	}
+	return e.ExpireAt.Sub(s.clock.Now()), true, nil
+}
+
+// NewFileStore creates a file store in dir, creating the directory if needed.
+//
+//	WithClock sets the clock used to compute expiry.
+func NewFileStore[K comparable, V any](dir string, opts ...ClockOption) (*FileStore[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	o := newCacheOptions(opts)
+	return &FileStore[K, V]{
+		dir:   dir,
+		clock: o.clock,
+	}, nil
+}
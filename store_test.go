@@ -0,0 +1,73 @@
+package wtype_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+func testStore(t *testing.T, s wtype.Store[string, int], clock *wtype.FakeClock) {
+	ctx := context.Background()
+
+	if _, ok, err := s.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("expected missing key, got (%v, %v)", ok, err)
+	}
+
+	if err := s.Set(ctx, "a", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "forever", 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := s.Get(ctx, "a"); !ok || err != nil || v != 1 {
+		t.Errorf("expected (1, true, nil), got (%d, %v, %v)", v, ok, err)
+	}
+
+	clock.Advance(20 * time.Second)
+	if ttl, ok, err := s.TTL(ctx, "a"); !ok || err != nil || ttl != 40*time.Second {
+		t.Errorf("expected (40s, true, nil), got (%v, %v, %v)", ttl, ok, err)
+	}
+	if ttl, ok, err := s.TTL(ctx, "forever"); !ok || err != nil || ttl != 0 {
+		t.Errorf("expected (0, true, nil), got (%v, %v, %v)", ttl, ok, err)
+	}
+
+	clock.Advance(40 * time.Second)
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("a should have expired")
+	}
+	if _, ok, _ := s.TTL(ctx, "a"); ok {
+		t.Error("TTL of an expired key should report missing")
+	}
+
+	if err := s.Delete(ctx, "forever"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "forever"); err != nil {
+		t.Errorf("deleting a missing key should not fail, got %v", err)
+	}
+	if _, ok, _ := s.Get(ctx, "forever"); ok {
+		t.Error("forever should have been deleted")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	testStore(t, wtype.NewMemoryStore[string, int](wtype.WithClock(clock)), clock)
+}
+
+func TestFileStore(t *testing.T) {
+	clock := wtype.NewFakeClock(clockStart)
+	s, err := wtype.NewFileStore[string, int](t.TempDir(), wtype.WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := s.Get(ctx, "a"); err == nil {
+		t.Error("expected a cancelled context to fail")
+	}
+}
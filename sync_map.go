@@ -2,6 +2,7 @@ package wtype
 
 import (
 	"encoding/json"
+	"iter"
 	"sync"
 )
 
@@ -81,6 +82,11 @@ func (s *SyncMap[K, V]) Range(f func(key K, value V) (shouldContinue bool)) {
 	})
 }
 
+// All returns an iterator over the key-value pairs of the map, with the guarantees of sync.Map.Range.
+func (s *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return s.Range
+}
+
 func (s *SyncMap[K, V]) Clear() {
 	s.m.Clear()
 }
@@ -88,3 +94,12 @@ func (s *SyncMap[K, V]) Clear() {
 func NewSyncMap[K comparable, V any]() *SyncMap[K, V] {
 	return &SyncMap[K, V]{}
 }
+
+// SyncMapFrom creates a new SyncMap with the key-value pairs of seq.
+func SyncMapFrom[K comparable, V any](seq iter.Seq2[K, V]) *SyncMap[K, V] {
+	s := NewSyncMap[K, V]()
+	for k, v := range seq {
+		s.Store(k, v)
+	}
+	return s
+}
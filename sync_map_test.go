@@ -2,6 +2,7 @@ package wtype_test
 
 import (
 	"encoding/json"
+	"maps"
 	"testing"
 
 	"github.com/wuchieh/wtype"
@@ -36,3 +37,12 @@ func TestSyncMap(t *testing.T) {
 	}
 	t.Log(actual, loaded)
 }
+
+func TestSyncMap_All(t *testing.T) {
+	want := map[string]int{"a": 1, "b": 2, "c": 3}
+	m := wtype.SyncMapFrom(maps.All(want))
+	got := maps.Collect(m.All())
+	if !maps.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,94 @@
+package wtype
+
+import (
+	"context"
+	"time"
+)
+
+// TieredCache is a two-tier cache: an in-memory KeyedCache (L1) in front of a Store (L2).
+//
+// Promotion policy: Get reads L1 first. On an L1 miss it reads L2, and a value found there is
+// copied into L1 for the L1 duration, or for the time L2 has left if that is shorter, so L1
+// never outlives L2.
+//
+// Write policy: Set is write-through. The value is written to L2 first and then to L1. If the
+// L2 write fails, the key is removed from L1 so it cannot serve a value L2 does not have.
+// Delete removes the key from L1 and then from L2.
+//
+// L1 is local to the process, so with a shared L2 other processes may read a stale L1 value
+// for up to the L1 duration after a write. Keep the L1 duration short when that matters.
+type TieredCache[K comparable, V any] struct {
+	l1    *KeyedCache[K, V]
+	l2    Store[K, V]
+	l2TTL time.Duration
+}
+
+// Get gets the value of key from L1, or from L2 on an L1 miss.
+func (c *TieredCache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	if v, ok := c.l1.Get(key); ok {
+		return v, true, nil
+	}
+
+	v, ok, err := c.l2.Get(ctx, key)
+	if err != nil || !ok {
+		return v, ok, err
+	}
+
+	ttl, ok, err := c.l2.TTL(ctx, key)
+	if err != nil || !ok {
+		// the value expired or vanished between the two calls; return what was read without promoting it
+		return v, true, nil
+	}
+	c.l1.SetWithDuration(key, v, c.l1Duration(ttl))
+	return v, true, nil
+}
+
+// l1Duration returns the L1 duration, shortened to l2Left so L1 never outlives L2.
+func (c *TieredCache[K, V]) l1Duration(l2Left time.Duration) time.Duration {
+	c.l1.mutex.RLock()
+	d := c.l1.d
+	c.l1.mutex.RUnlock()
+
+	if l2Left > 0 && (d == 0 || l2Left < d) {
+		return l2Left
+	}
+	return d
+}
+
+// Set writes the value of key to L2 and then to L1.
+func (c *TieredCache[K, V]) Set(ctx context.Context, key K, value V) error {
+	if err := c.l2.Set(ctx, key, value, c.l2TTL); err != nil {
+		c.l1.Delete(key)
+		return err
+	}
+	c.l1.SetWithDuration(key, value, c.l1Duration(c.l2TTL))
+	return nil
+}
+
+// Delete removes key from both tiers.
+func (c *TieredCache[K, V]) Delete(ctx context.Context, key K) error {
+	c.l1.Delete(key)
+	return c.l2.Delete(ctx, key)
+}
+
+// L1 returns the in-memory tier.
+func (c *TieredCache[K, V]) L1() *KeyedCache[K, V] {
+	return c.l1
+}
+
+// L2 returns the backing store.
+func (c *TieredCache[K, V]) L2() Store[K, V] {
+	return c.l2
+}
+
+// NewTieredCache creates a two-tier cache.
+//
+//	l1TTL is the lifetime of values in L1 and l2TTL the lifetime of values written to L2.
+//	If either is 0, values in that tier never expire. opts configure L1, such as WithCapacity.
+func NewTieredCache[K comparable, V any](l1TTL, l2TTL time.Duration, l2 Store[K, V], opts ...KeyedCacheOption) *TieredCache[K, V] {
+	return &TieredCache[K, V]{
+		l1:    NewKeyedCache[K, V](l1TTL, opts...),
+		l2:    l2,
+		l2TTL: l2TTL,
+	}
+}
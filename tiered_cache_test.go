@@ -0,0 +1,108 @@
+package wtype_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+// failingStore is a Store whose writes always fail.
+type failingStore[K comparable, V any] struct {
+	*wtype.MemoryStore[K, V]
+}
+
+var errStoreDown = errors.New("store down")
+
+func (failingStore[K, V]) Set(context.Context, K, V, time.Duration) error {
+	return errStoreDown
+}
+
+func TestTieredCache(t *testing.T) {
+	ctx := context.Background()
+	clock := wtype.NewFakeClock(clockStart)
+	l2 := wtype.NewMemoryStore[string, int](wtype.WithClock(clock))
+	c := wtype.NewTieredCache[string, int](time.Minute, time.Hour, l2, wtype.WithClock(clock))
+
+	t.Run("write-through", func(t *testing.T) {
+		if err := c.Set(ctx, "a", 1); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := c.L1().Get("a"); !ok || v != 1 {
+			t.Error("expected a in L1")
+		}
+		if v, ok, _ := l2.Get(ctx, "a"); !ok || v != 1 {
+			t.Error("expected a in L2")
+		}
+	})
+
+	t.Run("promotion", func(t *testing.T) {
+		clock.Advance(time.Minute) // a leaves L1 but stays in L2
+		if _, ok := c.L1().Get("a"); ok {
+			t.Fatal("a should have left L1")
+		}
+		if v, ok, err := c.Get(ctx, "a"); !ok || err != nil || v != 1 {
+			t.Fatalf("expected (1, true, nil), got (%d, %v, %v)", v, ok, err)
+		}
+		if _, ok := c.L1().Get("a"); !ok {
+			t.Error("a should have been promoted to L1")
+		}
+	})
+
+	t.Run("L1 never outlives L2", func(t *testing.T) {
+		_ = l2.Set(ctx, "short", 2, 10*time.Second)
+		if _, ok, _ := c.Get(ctx, "short"); !ok {
+			t.Fatal("expected short in L2")
+		}
+		if ttl, _ := c.L1().TTL("short"); ttl != 10*time.Second {
+			t.Errorf("expected L1 TTL of 10s, got %v", ttl)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := c.Delete(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, _ := c.Get(ctx, "a"); ok {
+			t.Error("a should have been deleted from both tiers")
+		}
+	})
+
+	t.Run("failed L2 write invalidates L1", func(t *testing.T) {
+		fc := wtype.NewTieredCache[string, int](time.Minute, time.Hour, failingStore[string, int]{l2})
+		fc.L1().Set("b", 1)
+		if err := fc.Set(ctx, "b", 2); !errors.Is(err, errStoreDown) {
+			t.Fatalf("expected store error, got %v", err)
+		}
+		if _, ok := fc.L1().Get("b"); ok {
+			t.Error("b should have been removed from L1")
+		}
+	})
+}
+
+// vanishingStore is a Store whose values vanish before their TTL can be read.
+type vanishingStore[K comparable, V any] struct {
+	*wtype.MemoryStore[K, V]
+}
+
+func (vanishingStore[K, V]) TTL(context.Context, K) (time.Duration, bool, error) {
+	return 0, false, errStoreDown
+}
+
+func TestTieredCache_VanishedBeforePromotion(t *testing.T) {
+	l2 := vanishingStore[string, int]{wtype.NewMemoryStore[string, int]()}
+	if err := l2.Set(context.Background(), "a", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	c := wtype.NewTieredCache[string, int](time.Minute, time.Hour, l2)
+
+	v, ok, err := c.Get(context.Background(), "a")
+	if v != 1 || !ok || err != nil {
+		t.Errorf("expected (1, true, nil), got (%d, %v, %v)", v, ok, err)
+	}
+	if _, ok := c.L1().Get("a"); ok {
+		t.Error("expected the value not to be promoted")
+	}
+}
@@ -3,6 +3,7 @@ package wtype
 import (
 	"context"
 	"fmt"
+	"iter"
 	"reflect"
 	"runtime"
 	"strings"
@@ -59,14 +60,17 @@ func DoSharedChan[T any](key string, fn func() (T, error)) <-chan SharedChanResu
 		return t, nil
 	})
 
-	result := make(chan SharedChanResult[T])
+	// buffered so the goroutine never blocks if the caller stops listening
+	result := make(chan SharedChanResult[T], 1)
 
 	go func() {
 		data := <-doChan
 
+		// Val is nil when fn returns an error
+		val, _ := Assert[T](data.Val)
 		result <- SharedChanResult[T]{
 			Result: data,
-			Val:    data.Val.(T),
+			Val:    val,
 		}
 	}()
 
@@ -245,6 +249,35 @@ func SliceConvert2[T, K any](s []T, f func(int, T) (K, bool)) []K {
 	return result
 }
 
+// SeqConvert is SliceConvert for an iterator.
+//
+//	f is called lazily as the returned iterator is consumed.
+func SeqConvert[T, K any](seq iter.Seq[T], f func(T) K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// SeqConvert2 is SliceConvert2 for an iterator; the int passed to f is the position of the element in seq.
+//
+//	If the function returns false, the element will be skipped.
+func SeqConvert2[T, K any](seq iter.Seq[T], f func(int, T) (K, bool)) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		i := 0
+		for v := range seq {
+			data, ok := f(i, v)
+			i++
+			if ok && !yield(data) {
+				return
+			}
+		}
+	}
+}
+
 // SlicePointConvert converts a slice of type *T to a slice of type K
 func SlicePointConvert[T any](s []T) []*T {
 	return SliceConvert(s, func(v T) *T {
@@ -383,3 +416,13 @@ func SliceGroupByKey[V any, K comparable](s []V, key func(V) K) map[K][]V {
 	}
 	return result
 }
+
+// SeqGroupByKey is SliceGroupByKey for an iterator.
+func SeqGroupByKey[V any, K comparable](seq iter.Seq[V], key func(V) K) map[K][]V {
+	result := make(map[K][]V)
+	for v := range seq {
+		k := key(v)
+		result[k] = append(result[k], v)
+	}
+	return result
+}
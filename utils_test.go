@@ -2,7 +2,9 @@ package wtype_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -546,3 +548,51 @@ func TestSliceGroupByKey(t *testing.T) {
 		t.Error("SliceGroupByKey error")
 	}
 }
+
+func TestDoSharedChan_Error(t *testing.T) {
+	errFailed := errors.New("failed")
+	result := <-wtype.DoSharedChan(time.Now().String()+"chan-error", func() (int, error) {
+		return 0, errFailed
+	})
+	if !errors.Is(result.Err, errFailed) {
+		t.Errorf("expected %v, got %v", errFailed, result.Err)
+	}
+	if result.Val != 0 {
+		t.Errorf("expected zero value, got %d", result.Val)
+	}
+}
+
+func TestSeqConvert(t *testing.T) {
+	seq := wtype.SeqConvert(slices.Values([]int{1, 2, 3}), strconv.Itoa)
+	if got := slices.Collect(seq); !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Errorf("expected [1 2 3], got %q", got)
+	}
+
+	calls := 0
+	for range wtype.SeqConvert(slices.Values([]int{1, 2, 3}), func(v int) int {
+		calls++
+		return v
+	}) {
+		break
+	}
+	if calls != 1 {
+		t.Errorf("expected f to be called lazily once, got %d", calls)
+	}
+}
+
+func TestSeqConvert2(t *testing.T) {
+	seq := wtype.SeqConvert2(slices.Values([]string{"a", "b", "c", "d"}), func(i int, s string) (string, bool) {
+		return fmt.Sprintf("%d%s", i, s), i%2 == 0
+	})
+	if got := slices.Collect(seq); !slices.Equal(got, []string{"0a", "2c"}) {
+		t.Errorf("expected [0a 2c], got %q", got)
+	}
+}
+
+func TestSeqGroupByKey(t *testing.T) {
+	got := wtype.SeqGroupByKey(slices.Values([]int{1, 2, 3, 4, 5}), func(v int) bool { return v%2 == 0 })
+	want := map[bool][]int{true: {2, 4}, false: {1, 3, 5}}
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,233 @@
+package wtype
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrCacheClosed is returned when values are written to a write-behind cache after Close.
+var ErrCacheClosed = errors.New("wtype: cache is closed")
+
+// Sink persists the values set in a write-through or write-behind cache.
+type Sink[T any] interface {
+	Write(ctx context.Context, values []T) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc[T any] func(ctx context.Context, values []T) error
+
+// Write calls f(ctx, values).
+func (f SinkFunc[T]) Write(ctx context.Context, values []T) error {
+	return f(ctx, values)
+}
+
+// WriteThroughCache persists every value to a Sink before it is set in the wrapped cache.
+//
+//	If the sink fails, the cache is left unchanged. Set reports the error to the function set by
+//	WithErrorHandler; use SetWithError to get it instead. Sets are serialized, so the last value
+//	written to the sink is always the one in the cache.
+type WriteThroughCache[T any] struct {
+	cache   ICache[T]
+	sink    Sink[T]
+	onError func(error)
+	mutex   sync.Mutex // held across the sink write and the cache update
+}
+
+// Set writes data to the sink and then sets it in the cache.
+func (c *WriteThroughCache[T]) Set(data T) {
+	if err := c.SetWithError(data); err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// SetWithError writes data to the sink and then sets it in the cache, returning the error of the sink.
+func (c *WriteThroughCache[T]) SetWithError(data T) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.sink.Write(context.Background(), []T{data}); err != nil {
+		return err
+	}
+	c.cache.Set(data)
+	return nil
+}
+
+// Get gets the data of the cache.
+func (c *WriteThroughCache[T]) Get() T {
+	return c.cache.Get()
+}
+
+// SetDuration sets the duration of the cache.
+func (c *WriteThroughCache[T]) SetDuration(d time.Duration) {
+	c.cache.SetDuration(d)
+}
+
+// ResetTimer resets the timer of the cache.
+func (c *WriteThroughCache[T]) ResetTimer() {
+	c.cache.ResetTimer()
+}
+
+// StopTimer stops the timer of the cache.
+func (c *WriteThroughCache[T]) StopTimer() {
+	c.cache.StopTimer()
+}
+
+// NewWriteThroughCache wraps cache, such as a CustomCache, so that every Set is written to sink first.
+//
+//	WithErrorHandler sets the function called when a write fails with no caller to return the error to.
+func NewWriteThroughCache[T any](cache ICache[T], sink Sink[T], opts ...ErrorHandlerOption) *WriteThroughCache[T] {
+	return &WriteThroughCache[T]{
+		cache:   cache,
+		sink:    sink,
+		onError: newCacheOptions(opts).onError,
+	}
+}
+
+// WriteBehindCache sets values in the wrapped cache at once and writes them to a Sink later, in batches.
+//
+//	A batch is written when the queue reaches the batch size, when the flush interval has passed since its first
+//	value was queued, or when Flush or Close is called. Batches are written in order, one at a time.
+//	A failed batch is dropped and its error is passed to the function set by WithErrorHandler.
+type WriteBehindCache[T any] struct {
+	cache    ICache[T]
+	sink     Sink[T]
+	batch    int
+	interval time.Duration
+	clock    Clock
+	onError  func(error)
+
+	pending []T
+	t       ClockTimer
+	closed  bool
+	mutex   sync.Mutex
+
+	writing sync.Mutex // held while a batch is written, to keep batches in order
+	flights sync.WaitGroup
+}
+
+// Set sets data in the cache and queues it to be written to the sink.
+//
+//	The cache is updated and the value queued under the same lock, so the queue ends with the value
+//	in the cache. After Close, data is still set in the cache but ErrCacheClosed is passed to the error handler.
+func (c *WriteBehindCache[T]) Set(data T) {
+	c.mutex.Lock()
+	c.cache.Set(data)
+	if c.closed {
+		c.mutex.Unlock()
+		c.report(ErrCacheClosed)
+		return
+	}
+	c.pending = append(c.pending, data)
+	full := c.batch > 0 && len(c.pending) >= c.batch
+	if full {
+		c.flights.Add(1)
+	} else if c.t == nil && c.interval > 0 {
+		c.t = c.clock.AfterFunc(c.interval, c.flushInBackground)
+	}
+	c.mutex.Unlock()
+
+	if full {
+		go func() {
+			defer c.flights.Done()
+			c.flushInBackground()
+		}()
+	}
+}
+
+// Get gets the data of the cache.
+func (c *WriteBehindCache[T]) Get() T {
+	return c.cache.Get()
+}
+
+// SetDuration sets the duration of the cache.
+func (c *WriteBehindCache[T]) SetDuration(d time.Duration) {
+	c.cache.SetDuration(d)
+}
+
+// ResetTimer resets the timer of the cache.
+func (c *WriteBehindCache[T]) ResetTimer() {
+	c.cache.ResetTimer()
+}
+
+// StopTimer stops the timer of the cache.
+func (c *WriteBehindCache[T]) StopTimer() {
+	c.cache.StopTimer()
+}
+
+// Pending returns the number of values waiting to be written.
+func (c *WriteBehindCache[T]) Pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.pending)
+}
+
+// Flush writes the queued values to the sink, in batches of at most the batch size, and returns the errors of the sink.
+func (c *WriteBehindCache[T]) Flush() error {
+	c.writing.Lock()
+	defer c.writing.Unlock()
+
+	c.mutex.Lock()
+	values := c.pending
+	c.pending = nil
+	if c.t != nil {
+		c.t.Stop()
+		c.t = nil
+	}
+	c.mutex.Unlock()
+
+	if len(values) == 0 {
+		return nil
+	}
+	if c.batch <= 0 {
+		return c.sink.Write(context.Background(), values)
+	}
+	var errs []error
+	for batch := range slices.Chunk(values, c.batch) {
+		if err := c.sink.Write(context.Background(), batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops queueing values and writes the queued ones to the sink.
+//
+//	It waits for batches being written in the background and returns the error of the final write.
+func (c *WriteBehindCache[T]) Close() error {
+	c.mutex.Lock()
+	c.closed = true
+	c.mutex.Unlock()
+
+	c.flights.Wait()
+	return c.Flush()
+}
+
+// flushInBackground is Flush for the batch size and interval triggers, which have no caller to return to.
+func (c *WriteBehindCache[T]) flushInBackground() {
+	c.report(c.Flush())
+}
+
+// report passes err to the error handler.
+func (c *WriteBehindCache[T]) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// NewWriteBehindCache wraps cache, such as a CustomCache, so that every Set is queued and written to sink in batches.
+//
+//	WithBatchSize, WithFlushInterval, WithClock and WithErrorHandler configure the queue.
+//	Call Close when done so queued values are not lost.
+func NewWriteBehindCache[T any](cache ICache[T], sink Sink[T], opts ...WriteBehindOption) *WriteBehindCache[T] {
+	o := newCacheOptions(opts)
+	return &WriteBehindCache[T]{
+		cache:    cache,
+		sink:     sink,
+		batch:    o.batch,
+		interval: o.interval,
+		clock:    o.clock,
+		onError:  o.onError,
+	}
+}
@@ -0,0 +1,181 @@
+package wtype_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wuchieh/wtype"
+)
+
+// recordingSink collects the batches written to it.
+type recordingSink[T any] struct {
+	mutex   sync.Mutex
+	batches [][]T
+	err     error
+}
+
+func (s *recordingSink[T]) Write(_ context.Context, values []T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, slices.Clone(values))
+	return nil
+}
+
+func (s *recordingSink[T]) values() []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Concat(s.batches...)
+}
+
+func TestWriteThroughCache(t *testing.T) {
+	sink := &recordingSink[int]{}
+	c := wtype.NewWriteThroughCache[int](wtype.NewCache[int](0), sink)
+
+	c.Set(1)
+	c.Set(2)
+	if c.Get() != 2 {
+		t.Errorf("expected 2, got %d", c.Get())
+	}
+	if got := sink.values(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("expected [1 2] to be written, got %v", got)
+	}
+
+	errFail := errors.New("fail")
+	sink.err = errFail
+	if err := c.SetWithError(3); !errors.Is(err, errFail) {
+		t.Errorf("expected errFail, got %v", err)
+	}
+	if c.Get() != 2 {
+		t.Errorf("expected the cache to be unchanged after a failed write, got %d", c.Get())
+	}
+
+	var reported error
+	c = wtype.NewWriteThroughCache[int](wtype.NewCache[int](0), sink, wtype.WithErrorHandler(func(err error) {
+		reported = err
+	}))
+	c.Set(4)
+	if !errors.Is(reported, errFail) {
+		t.Errorf("expected errFail to be reported, got %v", reported)
+	}
+}
+
+func TestWriteBehindCache(t *testing.T) {
+	t.Run("batch size", func(t *testing.T) {
+		sink := &recordingSink[int]{}
+		c := wtype.NewWriteBehindCache[int](wtype.NewCache[int](0), sink,
+			wtype.WithBatchSize(3), wtype.WithFlushInterval(0))
+		for i := 1; i <= 7; i++ {
+			c.Set(i)
+		}
+		if c.Get() != 7 {
+			t.Errorf("expected 7, got %d", c.Get())
+		}
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := sink.values(); !slices.Equal(got, []int{1, 2, 3, 4, 5, 6, 7}) {
+			t.Errorf("expected all values in order, got %v", got)
+		}
+		if len(sink.batches) != 3 {
+			t.Errorf("expected 3 batches, got %d", len(sink.batches))
+		}
+	})
+
+	t.Run("flush interval", func(t *testing.T) {
+		clock := wtype.NewFakeClock(clockStart)
+		sink := &recordingSink[string]{}
+		c := wtype.NewWriteBehindCache[string](wtype.NewCache[string](0), sink,
+			wtype.WithFlushInterval(time.Second), wtype.WithClock(clock))
+		c.Set("a")
+		c.Set("b")
+		if c.Pending() != 2 || len(sink.values()) != 0 {
+			t.Fatalf("expected 2 queued values, got %d pending and %v written", c.Pending(), sink.values())
+		}
+		clock.Advance(time.Second)
+		if got := sink.values(); !slices.Equal(got, []string{"a", "b"}) {
+			t.Errorf("expected [a b] after the interval, got %v", got)
+		}
+		if c.Pending() != 0 {
+			t.Errorf("expected no pending values, got %d", c.Pending())
+		}
+	})
+
+	t.Run("errors and close", func(t *testing.T) {
+		errFail := errors.New("fail")
+		sink := &recordingSink[int]{err: errFail}
+		var reported []error
+		c := wtype.NewWriteBehindCache[int](wtype.NewCache[int](0), sink,
+			wtype.WithFlushInterval(0), wtype.WithErrorHandler(func(err error) {
+				reported = append(reported, err)
+			}))
+		c.Set(1)
+		if err := c.Flush(); !errors.Is(err, errFail) {
+			t.Errorf("expected errFail from Flush, got %v", err)
+		}
+
+		sink.err = nil
+		c.Set(2)
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := sink.values(); !slices.Equal(got, []int{2}) {
+			t.Errorf("expected Close to write [2], got %v", got)
+		}
+
+		c.Set(3)
+		if c.Get() != 3 {
+			t.Errorf("expected the cache to be set after Close, got %d", c.Get())
+		}
+		if len(reported) != 1 || !errors.Is(reported[0], wtype.ErrCacheClosed) {
+			t.Errorf("expected ErrCacheClosed to be reported, got %v", reported)
+		}
+	})
+}
+
+func TestWriteCache_ConcurrentSet(t *testing.T) {
+	t.Run("write-through", func(t *testing.T) {
+		sink := &recordingSink[int]{}
+		c := wtype.NewWriteThroughCache[int](wtype.NewSafeCache[int](0), sink)
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Set(i)
+			}()
+		}
+		wg.Wait()
+		values := sink.values()
+		if last := values[len(values)-1]; last != c.Get() {
+			t.Errorf("expected the sink to end with the cached %d, got %d", c.Get(), last)
+		}
+	})
+
+	t.Run("write-behind", func(t *testing.T) {
+		sink := &recordingSink[int]{}
+		c := wtype.NewWriteBehindCache[int](wtype.NewSafeCache[int](0), sink, wtype.WithFlushInterval(0))
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Set(i)
+			}()
+		}
+		wg.Wait()
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+		values := sink.values()
+		if last := values[len(values)-1]; last != c.Get() {
+			t.Errorf("expected the sink to end with the cached %d, got %d", c.Get(), last)
+		}
+	})
+}